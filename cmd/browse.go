@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse and edit channels in a line-based prompt mode",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			return browse(cp, os.Stdin)
+		})
+	},
+}
+
+const browseHelp = `Commands:
+  l              list channels
+  n, p           show the next or previous channel
+  <index>        show a channel
+  e <field> <v>  edit a field of the current channel; the value is the
+                 rest of the line, e.g. e name Foo Bar
+  h              show this help
+  q              quit`
+
+func browse(cp *codeplug.Codeplug, in io.Reader) error {
+	channels, err := cp.GetChannels()
+	if err != nil {
+		return fmt.Errorf("failed to get channels: %w", err)
+	}
+	if len(channels) == 0 {
+		return fmt.Errorf("codeplug has no channels")
+	}
+
+	reader := bufio.NewReader(in)
+	current := 0
+
+	for _, channel := range channels {
+		printChannelSummary(channel)
+	}
+	fmt.Println()
+	fmt.Println(browseHelp)
+
+	for {
+		fmt.Printf("\n[%d/%d] > ", current, len(channels)-1)
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				fmt.Println()
+				return nil
+			}
+			return err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "q", "quit":
+			return nil
+		case "h", "help":
+			fmt.Println(browseHelp)
+		case "l", "list":
+			for _, channel := range channels {
+				printChannelSummary(channel)
+			}
+		case "n", "next":
+			if current < len(channels)-1 {
+				current++
+			}
+			printChannelDetail(channels[current])
+		case "p", "prev":
+			if current > 0 {
+				current--
+			}
+			printChannelDetail(channels[current])
+		case "e", "edit":
+			field, value, ok := parseBrowseEdit(line)
+			if !ok {
+				fmt.Println("usage: e <field> <value>")
+				continue
+			}
+			setter, ok := channelSetters[field]
+			if !ok {
+				fmt.Printf("unknown channel field: %s\n", field)
+				continue
+			}
+			if !confirmPrompt(reader, fmt.Sprintf("Set %s of channel %d to %s?", field, current, value)) {
+				fmt.Println("Cancelled")
+				continue
+			}
+			if err := setter(cp, current, value); err != nil {
+				fmt.Printf("failed to update channel: %v\n", err)
+				continue
+			}
+			channel, err := cp.GetChannelByIndex(current)
+			if err != nil {
+				return fmt.Errorf("failed to reload channel: %w", err)
+			}
+			channels[current] = channel
+			printChannelDetail(channel)
+		default:
+			index, err := strconv.Atoi(fields[0])
+			if err != nil || index < 0 || index >= len(channels) {
+				fmt.Printf("unknown command or channel: %s\n", fields[0])
+				continue
+			}
+			current = index
+			printChannelDetail(channels[current])
+		}
+	}
+}
+
+// parseBrowseEdit splits an edit command into the field and its value. The
+// value is everything after the field, so names may contain spaces; a value
+// wrapped in double quotes has them removed.
+func parseBrowseEdit(line string) (field, value string, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return "", "", false
+	}
+
+	rest := strings.TrimSpace(line)
+	rest = strings.TrimSpace(rest[len(fields[0]):])
+	rest = strings.TrimSpace(rest[len(fields[1]):])
+	if len(rest) >= 2 && strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`) {
+		rest = rest[1 : len(rest)-1]
+	}
+	if rest == "" {
+		return "", "", false
+	}
+	return fields[1], rest, true
+}
+
+func confirmPrompt(reader *bufio.Reader, prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
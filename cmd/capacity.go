@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var capacityCmd = &cobra.Command{
+	Use:   "capacity",
+	Short: "Show how much room is left in each section",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkFormat(formatText, formatJSON); err != nil {
+			return err
+		}
+
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			sections, err := cp.Capacity()
+			if err != nil {
+				return fmt.Errorf("failed to read capacity: %w", err)
+			}
+
+			if outputFormat == formatJSON {
+				return printJSON(sections)
+			}
+
+			for _, s := range sections {
+				percent := 0
+				if s.Max > 0 {
+					percent = s.Used * 100 / s.Max
+				}
+				fmt.Printf("%5d / %-5d %s (%d%%)\n", s.Used, s.Max, s.Section, percent)
+			}
+			return nil
+		})
+	},
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var checkWritableCmd = &cobra.Command{
+	Use:   "check-writable",
+	Short: "Check that the codeplug can be written without changing it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		err := withCodeplug(func(cp *codeplug.Codeplug) error {
+			return cp.CheckWritable()
+		})
+		if err != nil {
+			return fmt.Errorf("%s is not writable: %w", codeplugFile, err)
+		}
+
+		fmt.Printf("%s is writable\n", codeplugFile)
+		return nil
+	},
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var clearForce bool
+
+var clearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every record of a section",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+var clearChannelsCmd = &cobra.Command{
+	Use:   "channels --force",
+	Short: "Remove all channels, keeping settings and radio IDs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !clearForce {
+			return fmt.Errorf("refusing to clear channels without --force")
+		}
+
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			backupPath, err := cp.Backup()
+			if err != nil {
+				return fmt.Errorf("failed to back up codeplug: %w", err)
+			}
+			fmt.Printf("Backup written to %s\n", backupPath)
+
+			removed, err := cp.ClearChannels()
+			if err != nil {
+				return fmt.Errorf("failed to clear channels: %w", err)
+			}
+
+			fmt.Printf("Successfully removed %d channels\n", removed)
+			return nil
+		})
+	},
+}
+
+func init() {
+	clearCmd.PersistentFlags().BoolVar(&clearForce, "force", false, "Confirm removing the records")
+
+	clearCmd.AddCommand(clearChannelsCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"os"
+)
+
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+var noColor bool
+
+func colorEnabled() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	stat, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+func colorize(color, text string) string {
+	if !colorEnabled() {
+		return text
+	}
+	return color + text + colorReset
+}
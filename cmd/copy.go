@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var copyChannelAt int
+
+var copyChannelCmd = &cobra.Command{
+	Use:   "copy-channel <src.rdt> <index> <dst.rdt>",
+	Short: "Copy one channel from one codeplug into another",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srcPath, dstPath := args[0], args[2]
+
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid index: %w", err)
+		}
+
+		if sameFile(srcPath, dstPath) {
+			return fmt.Errorf("source and destination are the same file")
+		}
+
+		src, err := openCodeplugReadOnly(srcPath)
+		if err != nil {
+			return fmt.Errorf("failed to open codeplug %s: %w", srcPath, err)
+		}
+		defer src.Close()
+
+		channel, err := src.GetChannelByIndex(index)
+		if err != nil {
+			return fmt.Errorf("failed to get channel: %w", err)
+		}
+		record, err := src.ReadChannelRecord(channel)
+		if err != nil {
+			return fmt.Errorf("failed to read channel: %w", err)
+		}
+		srcModel, err := src.Model()
+		if err != nil {
+			return err
+		}
+
+		dst, err := openCodeplug(dstPath)
+		if err != nil {
+			return fmt.Errorf("failed to open codeplug %s: %w", dstPath, err)
+		}
+		defer dst.Close()
+
+		dstModel, err := dst.Model()
+		if err != nil {
+			return err
+		}
+		if srcModel.Name != dstModel.Name {
+			fmt.Fprintf(os.Stderr, "warning: copying from %s to %s; fields this tool does not decode are copied unchanged\n", srcModel.Name, dstModel.Name)
+		}
+		for _, freq := range []uint32{channel.RxFreq, uint32(channel.TxFreq)} {
+			if err := dstModel.CheckFrequency(freq); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
+
+		at := copyChannelAt
+		if !cmd.Flags().Changed("at") {
+			if at, err = dst.NextFreeChannelIndex(); err != nil {
+				return err
+			}
+		}
+
+		if err := dst.InsertChannelRecord(at, record, srcModel); err != nil {
+			return fmt.Errorf("failed to copy channel: %w", err)
+		}
+
+		fmt.Printf("Successfully copied channel %d (%s) to %s as channel %d\n", index, channel.Name, dstPath, at)
+		return nil
+	},
+}
+
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(aInfo, bInfo)
+}
+
+func init() {
+	copyChannelCmd.Flags().IntVar(&copyChannelAt, "at", 0, "Insert before this channel index instead of appending")
+}
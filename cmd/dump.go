@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var dumpAll bool
+
+type dumpSection struct {
+	name string
+	read func(cp *codeplug.Codeplug, d *dumpDocument) error
+}
+
+type dumpDocument struct {
+	Model    string                   `json:"model,omitempty"`
+	Channels []*codeplug.Channel      `json:"channels,omitempty"`
+	RadioIDs []*codeplug.RadioIDEntry `json:"radio_ids,omitempty"`
+	Errors   map[string]string        `json:"errors,omitempty"`
+}
+
+var errSectionNotDecoded = errors.New("section is not decoded by this version")
+
+var dumpSections = []dumpSection{
+	{"model", func(cp *codeplug.Codeplug, d *dumpDocument) error {
+		info, err := cp.GetInfoWith(codeplug.InfoOptions{SkipRadioIDs: true})
+		if err != nil {
+			return err
+		}
+		d.Model = info.Model
+		return nil
+	}},
+	{"settings", notDecoded},
+	{"channels", func(cp *codeplug.Codeplug, d *dumpDocument) error {
+		channels, err := cp.GetChannels()
+		d.Channels = channels
+		return err
+	}},
+	{"radio_ids", func(cp *codeplug.Codeplug, d *dumpDocument) error {
+		radioIDs, err := cp.GetRadioIDs()
+		d.RadioIDs = radioIDs
+		return err
+	}},
+	{"contacts", notDecoded},
+	{"zones", notDecoded},
+	{"scan_lists", notDecoded},
+	{"rx_groups", notDecoded},
+}
+
+func notDecoded(cp *codeplug.Codeplug, d *dumpDocument) error {
+	return errSectionNotDecoded
+}
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump [section...] | dump --all",
+	Short: "Dump decoded codeplug sections as a single JSON document",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		if outputFormat != formatJSON {
+			return fmt.Errorf("dump only supports --format json")
+		}
+
+		sections, err := selectDumpSections(args)
+		if err != nil {
+			return err
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			doc := &dumpDocument{}
+			for _, section := range sections {
+				if err := section.read(cp, doc); err != nil {
+					if doc.Errors == nil {
+						doc.Errors = make(map[string]string)
+					}
+					doc.Errors[section.name] = err.Error()
+				}
+			}
+			return printJSON(doc)
+		})
+	},
+}
+
+func selectDumpSections(names []string) ([]dumpSection, error) {
+	if dumpAll {
+		return dumpSections, nil
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("name the sections to dump or pass --all")
+	}
+
+	sections := make([]dumpSection, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, section := range dumpSections {
+			if section.name == name {
+				sections = append(sections, section)
+				found = true
+				break
+			}
+		}
+		if !found {
+			known := make([]string, len(dumpSections))
+			for i, section := range dumpSections {
+				known[i] = section.name
+			}
+			return nil, fmt.Errorf("unknown section %q (known: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return sections, nil
+}
+
+func init() {
+	dumpCmd.Flags().BoolVar(&dumpAll, "all", false, "Dump every section")
+}
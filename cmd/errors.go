@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+)
+
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+const (
+	exitError      = 1
+	exitFileLocked = 2
+	exitParseError = 3
+	exitPartial    = 4
+)
+
+// errRecordsSkipped is returned after a best-effort listing that had to skip
+// unreadable records, so the exit code shows the output is incomplete.
+var errRecordsSkipped = errors.New("listing is incomplete")
+
+// errImportRejected is returned when an import has invalid rows, so none of
+// it was applied.
+var errImportRejected = errors.New("import was rejected")
+
+var errorFormat string
+
+type errorReport struct {
+	Error  string `json:"error"`
+	Code   int    `json:"code"`
+	Offset *int64 `json:"offset,omitempty"`
+}
+
+func exitCode(err error) int {
+	var parseErr *codeplug.ParseError
+	switch {
+	case errors.Is(err, codeplug.ErrFileLocked):
+		return exitFileLocked
+	case errors.Is(err, errRecordsSkipped), errors.Is(err, errImportRejected):
+		return exitPartial
+	case errors.As(err, &parseErr), errors.Is(err, codeplug.ErrFileTooSmall):
+		return exitParseError
+	default:
+		return exitError
+	}
+}
+
+// ReportError prints err to stderr in the selected error format and returns
+// the exit code the process should terminate with.
+func ReportError(err error) int {
+	code := exitCode(err)
+
+	if errorFormat != errorFormatJSON {
+		fmt.Fprintln(os.Stderr, err)
+		return code
+	}
+
+	report := errorReport{Error: err.Error(), Code: code}
+	var parseErr *codeplug.ParseError
+	if errors.As(err, &parseErr) {
+		report.Offset = &parseErr.Offset
+	}
+	json.NewEncoder(os.Stderr).Encode(report)
+	return code
+}
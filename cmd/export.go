@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export channels to other formats",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+var exportCountOnly bool
+
+// exportFileArg requires the output file, which --count-only does without.
+func exportFileArg(cmd *cobra.Command, args []string) error {
+	if exportCountOnly {
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// printExportCount prints how many channels an export would write. Only the
+// channel count is read, not the records.
+func printExportCount() error {
+	return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+		info, err := cp.GetInfoWith(codeplug.InfoOptions{SkipRadioIDs: true})
+		if err != nil {
+			return fmt.Errorf("failed to read codeplug: %w", err)
+		}
+		fmt.Println(info.ChannelCount)
+		return nil
+	})
+}
+
+var exportCardCmd = &cobra.Command{
+	Use:   "card <out.txt>",
+	Short: "Write a printable channel reference card",
+	Args:  exportFileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportCountOnly {
+			return printExportCount()
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+
+			if args[0] == "-" {
+				return writeChannelCard(os.Stdout, channels)
+			}
+
+			out, err := os.Create(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", args[0], err)
+			}
+			if err := writeChannelCard(out, channels); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", args[0], err)
+			}
+
+			fmt.Printf("Wrote %d channels to %s\n", len(channels), args[0])
+			return nil
+		})
+	},
+}
+
+var exportColumns []string
+
+var exportChannelsCmd = &cobra.Command{
+	Use:   "channels <out.csv>",
+	Short: "Write every channel to a CSV file for spreadsheet editing",
+	Args:  exportFileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportCountOnly {
+			return printExportCount()
+		}
+
+		if err := codeplug.CheckCSVColumns(exportColumns); err != nil {
+			return err
+		}
+		opts := codeplug.CSVOptions{Columns: exportColumns}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+
+			err = writeExportFile(args[0], func(w io.Writer) error {
+				return codeplug.ExportChannelsCSVWith(w, channels, opts)
+			})
+			if err != nil || args[0] == "-" {
+				return err
+			}
+
+			fmt.Printf("Wrote %d channels to %s\n", len(channels), args[0])
+			return nil
+		})
+	},
+}
+
+var exportOpenGD77Cmd = &cobra.Command{
+	Use:   "opengd77 <out.csv>",
+	Short: "Write every channel as an OpenGD77 CPS channel CSV",
+	Args:  exportFileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportCountOnly {
+			return printExportCount()
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+
+			err = writeExportFile(args[0], func(w io.Writer) error {
+				return codeplug.ExportOpenGD77CSV(w, channels)
+			})
+			if err != nil || args[0] == "-" {
+				return err
+			}
+
+			fmt.Printf("Wrote %d channels to %s\n", len(channels), args[0])
+			return nil
+		})
+	},
+}
+
+// writeExportFile creates path and fills it with write, or writes to stdout
+// when path is "-".
+func writeExportFile(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(out); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeChannelCard(w io.Writer, channels []*codeplug.Channel) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CH\tNAME\tRX\tTX\tMODE\tTONE\tSLOT")
+	for _, channel := range channels {
+		slot := "-"
+		if channel.ChannelType != codeplug.ChannelTypeAnalog {
+			slot = fmt.Sprintf("TS%d", channel.Slot+1)
+		}
+
+		tone := "-"
+		if channel.CtcssDcsEncodeOption != codeplug.ToneOff {
+			tone = channel.DecodeTxTone()
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", channel.Index, channel.Name,
+			formatMHz(int64(channel.RxFreq)), formatMHz(int64(channel.TxFreq)), channel.ModeLabel(), tone, slot)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write channel card: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	exportCmd.PersistentFlags().BoolVar(&exportCountOnly, "count-only", false, "Print only the number of channels that would be exported")
+	exportCardCmd.Flags().BoolVar(&trimZeros, "trim-zeros", false, "Print frequencies without trailing zeros")
+	exportChannelsCmd.Flags().StringSliceVar(&exportColumns, "columns", nil, "Comma-separated columns to write, in order (default all)")
+
+	exportCmd.AddCommand(exportCardCmd)
+	exportCmd.AddCommand(exportChannelsCmd)
+	exportCmd.AddCommand(exportOpenGD77Cmd)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var (
+	findContact  string
+	findRadioID  int
+	findScanList int
+	findOrphanID bool
+	findBadName  bool
+	findAprsRx   bool
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Find records by what they reference",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+type channelFilter func(c *codeplug.Channel) bool
+
+var findChannelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "List channels that reference a contact, radio ID or scan list",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var filters []channelFilter
+
+		if cmd.Flags().Changed("contact") {
+			contact, err := strconv.Atoi(findContact)
+			if err != nil {
+				return fmt.Errorf("contact names cannot be resolved yet; use the contact index")
+			}
+			filters = append(filters, func(c *codeplug.Channel) bool { return int(c.Contact) == contact })
+		}
+		if cmd.Flags().Changed("radio-id") {
+			filters = append(filters, func(c *codeplug.Channel) bool { return int(c.RadioId) == findRadioID })
+		}
+		if cmd.Flags().Changed("scanlist") {
+			filters = append(filters, func(c *codeplug.Channel) bool { return int(c.ScanList) == findScanList })
+		}
+
+		if findAprsRx {
+			filters = append(filters, func(c *codeplug.Channel) bool { return c.AprsRx != 0 })
+		}
+
+		if findOrphanID && findBadName {
+			return fmt.Errorf("--orphan-radio-id cannot be combined with --bad-name")
+		}
+
+		if len(filters) == 0 && !findOrphanID && !findBadName {
+			return fmt.Errorf("at least one search flag is required")
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			if findBadName {
+				model, err := cp.Model()
+				if err != nil {
+					return fmt.Errorf("failed to read model: %w", err)
+				}
+				return cp.ForEachChannel(func(channel *codeplug.Channel) error {
+					problems := model.ChannelNameProblems(channel.Name)
+					if len(problems) > 0 && matchesAll(channel, filters) {
+						fmt.Printf("%d: %q (%s)\n", channel.Index, channel.Name, strings.Join(problems, ", "))
+					}
+					return nil
+				})
+			}
+
+			if findOrphanID {
+				orphans, err := cp.OrphanRadioIDChannels()
+				if err != nil {
+					return fmt.Errorf("failed to check radio IDs: %w", err)
+				}
+				for _, channel := range orphans {
+					if matchesAll(channel, filters) {
+						fmt.Printf("%d: %s (radio ID %d)\n", channel.Index, channel.Name, channel.RadioId)
+					}
+				}
+				return nil
+			}
+
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+
+			for _, channel := range channels {
+				if matchesAll(channel, filters) {
+					fmt.Printf("%d: %s\n", channel.Index, channel.Name)
+				}
+			}
+			return nil
+		})
+	},
+}
+
+func matchesAll(channel *codeplug.Channel, filters []channelFilter) bool {
+	for _, f := range filters {
+		if !f(channel) {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	findChannelsCmd.Flags().StringVar(&findContact, "contact", "", "Contact index")
+	findChannelsCmd.Flags().IntVar(&findRadioID, "radio-id", 0, "Radio ID index")
+	findChannelsCmd.Flags().IntVar(&findScanList, "scanlist", 0, "Scan list index")
+	findChannelsCmd.Flags().BoolVar(&findOrphanID, "orphan-radio-id", false, "Channels whose radio ID index has no radio ID entry")
+	findChannelsCmd.Flags().BoolVar(&findAprsRx, "aprs-rx", false, "Channels with APRS receive turned on")
+	findChannelsCmd.Flags().BoolVar(&findBadName, "bad-name", false, "Channels whose name is too long or has characters the radio cannot display")
+
+	findCmd.AddCommand(findChannelsCmd)
+}
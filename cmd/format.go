@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+)
+
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
+)
+
+var (
+	outputFormat string
+	trimZeros    bool
+	compactJSON  bool
+)
+
+func checkFormat(allowed ...string) error {
+	for _, f := range allowed {
+		if outputFormat == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q (supported: %s)", outputFormat, strings.Join(allowed, ", "))
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	if !compactJSON {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+func formatMHz(freq int64) string {
+	return codeplug.FormatMHz(freq, trimZeros)
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/emerson000/anytone-cli/pkg/codeplug"
 	"github.com/spf13/cobra"
@@ -19,23 +22,76 @@ var getCmd = &cobra.Command{
 	},
 }
 
+var (
+	getChannelGroupBy    string
+	getChannelSkipErrors bool
+	getChannelVerbose    bool
+	getChannelMode       string
+)
+
 var getChannelCmd = &cobra.Command{
 	Use:   "channel [index]",
 	Short: "Get channel(s). If no index is provided, returns all channels.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cp, err := codeplug.Open(codeplugFile)
+		if err := checkFormat(formatText, formatJSON, formatJSONL); err != nil {
+			return err
+		}
+		if outputFormat != formatText && getChannelGroupBy != "" {
+			return fmt.Errorf("--group-by only applies to text output")
+		}
+
+		if len(args) > 0 && getChannelMode != "" {
+			return fmt.Errorf("--mode only applies when listing channels")
+		}
+		modeFilter, err := parseModeFilter(getChannelMode)
+		if err != nil {
+			return err
+		}
+
+		cp, err := openCodeplugReadOnly(codeplugFile)
 		if err != nil {
 			return fmt.Errorf("failed to open codeplug: %w", err)
 		}
 		defer cp.Close()
 
+		if len(args) == 0 && outputFormat == formatJSONL {
+			encoder := json.NewEncoder(os.Stdout)
+			return cp.ForEachChannel(func(channel *codeplug.Channel) error {
+				if !modeFilter(channel) {
+					return nil
+				}
+				return encoder.Encode(channel)
+			})
+		}
+
 		if len(args) == 0 {
-			channels, err := cp.GetChannels()
+			all, skipped, err := getChannelList(cp)
 			if err != nil {
-				return fmt.Errorf("failed to get channels: %w", err)
+				return err
+			}
+			channels := make([]*codeplug.Channel, 0, len(all))
+			for _, channel := range all {
+				if modeFilter(channel) {
+					channels = append(channels, channel)
+				}
 			}
-			for i, channel := range channels {
-				fmt.Printf("%d: %s (Rx: %.4f MHz, Tx: %.4f MHz)\n", i, channel.Name, float64(channel.RxFreq)/100000, float64(channel.TxFreq)/100000)
+			switch {
+			case outputFormat == formatJSON:
+				if err := printJSON(channels); err != nil {
+					return err
+				}
+			case getChannelGroupBy == "":
+				for _, channel := range channels {
+					printChannelSummary(channel)
+				}
+			case getChannelGroupBy == "band":
+				printChannelsByBand(channels)
+			default:
+				return fmt.Errorf("unsupported grouping: %s", getChannelGroupBy)
+			}
+			if skipped > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%w: skipped %d unreadable channel records", errRecordsSkipped, skipped)
 			}
 			return nil
 		}
@@ -50,29 +106,135 @@ var getChannelCmd = &cobra.Command{
 			return fmt.Errorf("failed to get channel: %w", err)
 		}
 
-		fmt.Printf("Channel %d:\n", index)
-		fmt.Printf("  Name: %s\n", channel.Name)
-		fmt.Printf("  Rx Frequency: %.4f MHz\n", float64(channel.RxFreq)/100000)
-		fmt.Printf("  Tx Frequency: %.4f MHz\n", float64(channel.TxFreq)/100000)
-		fmt.Printf("  Channel Type: %d\n", channel.ChannelType)
-		fmt.Printf("  Tx Power: %d\n", channel.TxPower)
-		fmt.Printf("  Bandwidth: %d\n", channel.Bandwidth)
-		fmt.Printf("  CTCSS/DCS Decode: %d\n", channel.CtcssDcsDecode)
-		fmt.Printf("  CTCSS/DCS Encode: %d\n", channel.CtcssDcsEncode)
-		fmt.Printf("  Radio ID: %d\n", channel.RadioId)
-		fmt.Printf("  Scan List: %d\n", channel.ScanList)
-		fmt.Printf("  Color Code: %d\n", channel.RxColorCode)
-		fmt.Printf("  Slot: %d\n", channel.Slot)
+		switch outputFormat {
+		case formatJSON:
+			return printJSON(channel)
+		case formatJSONL:
+			return json.NewEncoder(os.Stdout).Encode(channel)
+		}
+
+		printChannelDetail(channel)
 
 		return nil
 	},
 }
 
+// getChannelList reads every channel. With --skip-errors or --best-effort,
+// unreadable records are reported on stderr and counted instead of ending
+// the listing.
+func getChannelList(cp *codeplug.Codeplug) ([]*codeplug.Channel, int, error) {
+	if !getChannelSkipErrors {
+		channels, err := cp.GetChannels()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get channels: %w", err)
+		}
+		return channels, 0, nil
+	}
+
+	channels, skipped, err := cp.GetChannelsSkippingErrors()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get channels: %w", err)
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(os.Stderr, "warning: skipped channel %d: %v\n", s.Index, s.Err)
+	}
+	return channels, len(skipped), nil
+}
+
+func printChannelDetail(channel *codeplug.Channel) {
+	fmt.Printf("Channel %d:\n", channel.Index)
+	fmt.Printf("  Name: %s\n", channel.Name)
+	fmt.Printf("  Rx Frequency: %s MHz\n", formatMHz(int64(channel.RxFreq)))
+	fmt.Printf("  Tx Frequency: %s MHz\n", formatMHz(int64(channel.TxFreq)))
+	fmt.Printf("  Tx Direction: %s\n", channel.TxDirectionLabel())
+	fmt.Printf("  Channel Type: %s\n", channel.ModeLabel())
+	fmt.Printf("  Tx Power: %s\n", channel.PowerLabel())
+	fmt.Printf("  Bandwidth: %s\n", channel.BandwidthLabel())
+	fmt.Printf("  CTCSS/DCS Decode: %s\n", channel.DecodeRxTone())
+	fmt.Printf("  CTCSS/DCS Encode: %s\n", channel.DecodeTxTone())
+	fmt.Printf("  Radio ID: %d\n", channel.RadioId)
+	fmt.Printf("  Scan List: %d\n", channel.ScanList)
+	fmt.Printf("  Color Code: %d\n", channel.RxColorCode)
+	fmt.Printf("  Slot: %d\n", channel.Slot)
+	fmt.Printf("  Slot Suit: %d (raw)\n", channel.SlotSuit)
+	fmt.Printf("  Correct Frequency: %+d\n", channel.CorrectFreq)
+	fmt.Printf("  SMS Confirmation: %s\n", onOff(channel.SmsConfirmation))
+	fmt.Printf("  SMS Forbid: %s\n", onOff(channel.SmsForbid))
+	fmt.Printf("  Data ACK Disable: %s\n", onOff(channel.DataAckDisable))
+	fmt.Printf("  APRS RX: %s\n", onOff(channel.AprsRx))
+	fmt.Printf("  Encryption: %s\n", channel.EncryptionMode())
+	if getChannelVerbose {
+		fmt.Printf("  Record Offset: %d (0x%X)\n", channel.Offset, channel.Offset)
+		fmt.Printf("  Name Offset: %d (0x%X)\n", channel.NameOffset, channel.NameOffset)
+		fmt.Printf("  Name Length: %d (including terminator)\n", channel.NameLength)
+		fmt.Printf("  Total Length: %d\n", channel.TotalLength)
+	}
+}
+
+// parseModeFilter turns a --mode value (analog, digital, a+d or d+a) into a
+// predicate. An empty value matches every channel.
+func parseModeFilter(mode string) (func(*codeplug.Channel) bool, error) {
+	if mode == "" {
+		return func(*codeplug.Channel) bool { return true }, nil
+	}
+	channelType, err := codeplug.ParseChannelType(mode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --mode: %w", err)
+	}
+	return func(channel *codeplug.Channel) bool { return channel.ChannelType == channelType }, nil
+}
+
+func onOff(value byte) string {
+	if value == 0 {
+		return "off"
+	}
+	return "on"
+}
+
+func printChannelSummary(channel *codeplug.Channel) {
+	fmt.Printf("%d: %s [%s] (Rx: %s MHz, Tx: %s MHz)\n", channel.Index, channel.Name, channel.ModeLabel(), formatMHz(int64(channel.RxFreq)), formatMHz(int64(channel.TxFreq)))
+}
+
+func printChannelsByBand(channels []*codeplug.Channel) {
+	groups := make(map[string][]*codeplug.Channel)
+	for _, channel := range channels {
+		band := codeplug.BandName(channel.RxFreq)
+		groups[band] = append(groups[band], channel)
+	}
+
+	names := make([]string, 0, len(codeplug.Bands)+1)
+	for _, b := range codeplug.Bands {
+		names = append(names, b.Name)
+	}
+	names = append(names, codeplug.OtherBand)
+
+	first := true
+	for _, name := range names {
+		group := groups[name]
+		if len(group) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Println()
+		}
+		first = false
+
+		fmt.Printf("%s:\n", name)
+		for _, channel := range group {
+			printChannelSummary(channel)
+		}
+	}
+}
+
 var getRadioIDCmd = &cobra.Command{
 	Use:   "radio_id [index]",
 	Short: "Get radio ID(s). If no index is provided, returns all radio IDs.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cp, err := codeplug.Open(codeplugFile)
+		if err := checkFormat(formatText, formatJSON); err != nil {
+			return err
+		}
+
+		cp, err := openCodeplugReadOnly(codeplugFile)
 		if err != nil {
 			return fmt.Errorf("failed to open codeplug: %w", err)
 		}
@@ -83,6 +245,9 @@ var getRadioIDCmd = &cobra.Command{
 			if err != nil {
 				return fmt.Errorf("failed to get radio IDs: %w", err)
 			}
+			if outputFormat == formatJSON {
+				return printJSON(radioIDs)
+			}
 			for _, entry := range radioIDs {
 				fmt.Printf("%d: %d (%s)\n", entry.Index, entry.ID, entry.Name)
 			}
@@ -98,13 +263,86 @@ var getRadioIDCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to get radio ID: %w", err)
 		}
+		if outputFormat == formatJSON {
+			return printJSON(radioID)
+		}
 		fmt.Printf("%d: %d (%s)\n", index, radioID.ID, radioID.Name)
 
 		return nil
 	},
 }
 
+var getModelRaw bool
+
+type modelReport struct {
+	Name    string `json:"name,omitempty"`
+	ID      string `json:"id"`
+	Known   bool   `json:"known"`
+	Raw     string `json:"raw,omitempty"`
+	Variant string `json:"variant,omitempty"`
+}
+
+var getModelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Get the radio model the codeplug is for",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkFormat(formatText, formatJSON); err != nil {
+			return err
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			raw, err := cp.RawModel()
+			if err != nil {
+				return fmt.Errorf("failed to read model: %w", err)
+			}
+
+			id := strings.TrimRight(string(raw), "\x00 ")
+			model, known := codeplug.LookupModel(id)
+
+			if outputFormat == formatJSON {
+				report := modelReport{ID: id, Known: known}
+				if known {
+					report.Name = model.Name
+				}
+				if getModelRaw {
+					report.Raw = fmt.Sprintf("% x", raw)
+					report.Variant = codeplug.ModelVariant(id)
+				}
+				return printJSON(report)
+			}
+
+			if known {
+				fmt.Printf("Model: %s (%s)\n", model.Name, model.ID)
+			} else {
+				fmt.Printf("Model: unrecognized (%q)\n", id)
+			}
+
+			if getModelRaw {
+				fmt.Printf("Raw: % x\n", raw)
+				if variant := codeplug.ModelVariant(id); variant != "" {
+					fmt.Printf("Variant: %s\n", variant)
+				} else if known {
+					fmt.Println("Variant: none")
+				}
+			}
+			return nil
+		})
+	},
+}
+
 func init() {
+	getCmd.PersistentFlags().BoolVar(&trimZeros, "trim-zeros", false, "Print frequencies without trailing zeros")
+
+	getChannelCmd.Flags().BoolVar(&getChannelSkipErrors, "skip-errors", false, "Skip unreadable channel records instead of failing")
+	getChannelCmd.Flags().BoolVar(&getChannelSkipErrors, "best-effort", false, "List every readable channel, noting skipped records (same as --skip-errors)")
+	getChannelCmd.Flags().BoolVarP(&getChannelVerbose, "verbose", "v", false, "Also show where the record was found in the file")
+	getChannelCmd.Flags().StringVar(&getChannelMode, "mode", "", "List only channels of this type (analog, digital, a+d, d+a)")
+	getChannelCmd.Flags().StringVar(&getChannelGroupBy, "group-by", "", "Group the channel listing (supported: band)")
+
+	getModelCmd.Flags().BoolVar(&getModelRaw, "raw", false, "Also print the stored model bytes and any variant suffix")
+
+	getCmd.AddCommand(getModelCmd)
 	getCmd.AddCommand(getRadioIDCmd)
 	getCmd.AddCommand(getChannelCmd)
 }
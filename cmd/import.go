@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Update records from other formats",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+var importDryRun bool
+
+var importChannelsCmd = &cobra.Command{
+	Use:   "channels <in.csv>",
+	Short: "Update channels from a CSV file written by export channels",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChannelImport(cmd, args[0], codeplug.PlanChannelsCSV)
+	},
+}
+
+var importChirpCmd = &cobra.Command{
+	Use:   "chirp <in.csv>",
+	Short: "Add or update analog channels from a CHIRP CSV export",
+	Long: `Add or update analog channels from a CSV file exported by CHIRP.
+
+A row whose Location is an existing channel index updates that channel; other
+rows are added after the last channel. Rows CHIRP describes but this radio
+cannot take from it, such as DMR, D-STAR or AM channels, transmit disabled or
+reverse tone squelch, are skipped and listed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChannelImport(cmd, args[0], codeplug.PlanChirpCSV)
+	},
+}
+
+// runChannelImport plans an import from path with planImport, then either prints
+// it or backs up the codeplug and applies it.
+func runChannelImport(cmd *cobra.Command, path string, planImport func(*codeplug.Codeplug, io.Reader) (*codeplug.ChannelImport, error)) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer in.Close()
+
+	return withCodeplug(func(cp *codeplug.Codeplug) error {
+		plan, err := planImport(cp, in)
+		if err != nil {
+			return fmt.Errorf("failed to import channels: %w", err)
+		}
+
+		for _, skipped := range plan.Skipped {
+			fmt.Fprintf(os.Stderr, "skipped: %v\n", skipped)
+		}
+		if len(plan.Errors) > 0 {
+			for _, rowErr := range plan.Errors {
+				fmt.Fprintf(os.Stderr, "error: %v\n", rowErr)
+			}
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%w: %d rows are invalid, no changes were saved", errImportRejected, len(plan.Errors))
+		}
+
+		if importDryRun {
+			printImportPlan(plan)
+			return nil
+		}
+
+		backupPath, err := cp.Backup()
+		if err != nil {
+			return fmt.Errorf("failed to back up codeplug: %w", err)
+		}
+		fmt.Printf("Backup written to %s\n", backupPath)
+
+		result, err := plan.Apply(cp)
+		if err != nil {
+			return fmt.Errorf("failed to import channels: %w", err)
+		}
+
+		if result.Added > 0 {
+			fmt.Printf("Added %d channels\n", result.Added)
+		}
+		fmt.Printf("Updated %d channels, %d unchanged\n", result.Updated, result.Unchanged)
+		return nil
+	})
+}
+
+// printImportPlan lists the channels an import would change and the decoded
+// fields that would differ.
+func printImportPlan(plan *codeplug.ChannelImport) {
+	added, unchanged := 0, 0
+	for _, update := range plan.Updates {
+		if !update.Changed() {
+			unchanged++
+			continue
+		}
+		if update.New {
+			added++
+			fmt.Printf("New channel %d\n", update.Before.Index)
+		} else {
+			fmt.Printf("Channel %d: %s\n", update.Before.Index, update.Before.Name)
+		}
+		for _, f := range codeplug.ChannelFields {
+			if old, new := f.Format(update.Before), f.Format(&update.After); old != new {
+				fmt.Printf("  %s: %s → %s\n", f.Name, old, new)
+			}
+		}
+	}
+	if added > 0 {
+		fmt.Printf("Would add %d channels\n", added)
+	}
+	fmt.Printf("Would update %d channels, %d unchanged; no changes were saved\n", len(plan.Updates)-added-unchanged, unchanged)
+}
+
+func init() {
+	importChannelsCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Validate the CSV and show the changes without writing them")
+	importChirpCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Validate the CSV and show the changes without writing them")
+
+	importCmd.AddCommand(importChannelsCmd)
+	importCmd.AddCommand(importChirpCmd)
+}
@@ -2,36 +2,141 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/emerson000/anytone-cli/pkg/codeplug"
 	"github.com/spf13/cobra"
 )
 
+var (
+	globPattern  string
+	infoUseCache bool
+	channelsOnly bool
+)
+
+type fileInfo struct {
+	File  string         `json:"file"`
+	Info  *codeplug.Info `json:"info,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Display information about the codeplug",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkFormat(formatText, formatJSON); err != nil {
+			return err
+		}
+
+		if infoUseCache && channelsOnly {
+			return fmt.Errorf("--cache cannot be combined with --channels-only")
+		}
+
+		read := readInfo
+		if infoUseCache {
+			cache, err := loadInfoCache()
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := cache.save(); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				}
+			}()
+			read = cache.readInfo
+		}
+
+		if globPattern != "" {
+			return runInfoGlob(read)
+		}
+
+		if codeplugFile == "" {
+			codeplugFile = offerRecentFile()
+		}
 		if codeplugFile == "" {
 			return fmt.Errorf("codeplug file path is required")
 		}
 
-		cp, err := codeplug.Open(codeplugFile)
+		info, err := read(codeplugFile)
 		if err != nil {
-			return fmt.Errorf("failed to open codeplug: %w", err)
+			return err
+		}
+
+		if outputFormat == formatJSON {
+			return printJSON(info)
 		}
-		defer cp.Close()
 
-		info, err := cp.GetInfo()
+		printInfo(info)
+		return nil
+	},
+}
+
+func readInfo(path string) (*codeplug.Info, error) {
+	cp, err := openCodeplugReadOnly(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open codeplug: %w", err)
+	}
+	defer cp.Close()
+
+	info, err := cp.GetInfoWith(codeplug.InfoOptions{SkipRadioIDs: channelsOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codeplug info: %w", err)
+	}
+
+	return info, nil
+}
+
+func printInfo(info *codeplug.Info) {
+	fmt.Printf("Model: %s\n", info.Model)
+	fmt.Printf("Channels: %d\n", info.ChannelCount)
+	if channelsOnly {
+		return
+	}
+	fmt.Printf("Radio IDs:\n")
+	for i, id := range info.RadioIDs {
+		fmt.Printf("  %d: %d\n", info.RadioIDIndices[i], id)
+	}
+}
+
+func runInfoGlob(read func(path string) (*codeplug.Info, error)) error {
+	paths, err := filepath.Glob(globPattern)
+	if err != nil {
+		return fmt.Errorf("invalid glob pattern: %w", err)
+	}
+
+	results := make([]fileInfo, 0, len(paths))
+	for _, path := range paths {
+		result := fileInfo{File: path}
+		info, err := read(path)
 		if err != nil {
-			return fmt.Errorf("failed to get codeplug info: %w", err)
+			result.Error = err.Error()
+		} else {
+			result.Info = info
 		}
+		results = append(results, result)
+	}
 
-		fmt.Printf("Model: %s\n", info.Model)
-		fmt.Printf("Radio IDs:\n")
-		for i, id := range info.RadioIDs {
-			fmt.Printf("  %d: %d\n", info.RadioIDIndices[i], id)
+	if outputFormat == formatJSON {
+		return printJSON(results)
+	}
+
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", result.File)
+		if result.Error != "" {
+			fmt.Printf("Error: %s\n", result.Error)
+			continue
 		}
+		printInfo(result.Info)
+	}
 
-		return nil
-	},
+	return nil
+}
+
+func init() {
+	infoCmd.Flags().BoolVar(&channelsOnly, "channels-only", false, "Skip locating and reading the radio ID section")
+	infoCmd.Flags().BoolVar(&infoUseCache, "cache", false, "Reuse results for files whose size and modification time are unchanged")
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+)
+
+// infoCacheEntry records the Info read from a file together with the size
+// and modification time it had, so a later run can tell whether it changed.
+type infoCacheEntry struct {
+	Size    int64          `json:"size"`
+	ModTime time.Time      `json:"mod_time"`
+	Info    *codeplug.Info `json:"info"`
+}
+
+type infoCache struct {
+	path    string
+	entries map[string]infoCacheEntry
+	dirty   bool
+}
+
+func loadInfoCache() (*infoCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+
+	cache := &infoCache{
+		path:    filepath.Join(dir, "anytone-cli", "info.json"),
+		entries: make(map[string]infoCacheEntry),
+	}
+
+	data, err := os.ReadFile(cache.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read info cache: %w", err)
+	}
+
+	// A corrupt cache is only a lost speedup, so start over rather than fail.
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		cache.entries = make(map[string]infoCacheEntry)
+	}
+
+	return cache, nil
+}
+
+func (c *infoCache) readInfo(path string) (*codeplug.Info, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open codeplug: %w", err)
+	}
+
+	if entry, ok := c.entries[key]; ok && entry.Size == stat.Size() && entry.ModTime.Equal(stat.ModTime()) {
+		return entry.Info, nil
+	}
+
+	info, err := readInfo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries[key] = infoCacheEntry{Size: stat.Size(), ModTime: stat.ModTime(), Info: info}
+	c.dirty = true
+	return info, nil
+}
+
+func (c *infoCache) save() error {
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write info cache: %w", err)
+	}
+	return nil
+}
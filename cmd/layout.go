@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var layoutCmd = &cobra.Command{
+	Use:   "layout",
+	Short: "Show where each decoded section lives in the file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkFormat(formatText, formatJSON); err != nil {
+			return err
+		}
+
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			layout, err := cp.Layout()
+			if err != nil {
+				return fmt.Errorf("failed to read layout: %w", err)
+			}
+
+			if outputFormat == formatJSON {
+				return printJSON(layout)
+			}
+
+			fmt.Printf("Header:    0x%06X-0x%06X (%d bytes)\n", 0, layout.HeaderSize, layout.HeaderSize)
+			fmt.Printf("Channels:  0x%06X-0x%06X (%d bytes)\n", layout.ChannelsStart, layout.ChannelsEnd, layout.ChannelsEnd-layout.ChannelsStart)
+			fmt.Printf("Radio IDs: 0x%06X-0x%06X (%d bytes)\n", layout.RadioIDsStart, layout.RadioIDsEnd, layout.RadioIDsEnd-layout.RadioIDsStart)
+			fmt.Printf("File size: %d bytes\n", layout.FileSize)
+			return nil
+		})
+	},
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+const (
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+	conflictRename    = "rename"
+	conflictPrompt    = "prompt"
+)
+
+var mergeOnConflict string
+
+type mergeSummary struct {
+	added, identical, overwritten, renamed, skipped int
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <src.rdt> <dst.rdt>",
+	Short: "Merge the channels of one codeplug into another",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srcPath, dstPath := args[0], args[1]
+
+		switch mergeOnConflict {
+		case conflictSkip, conflictOverwrite, conflictRename:
+		case conflictPrompt:
+			if !stdinIsTerminal() {
+				return fmt.Errorf("--on-conflict prompt needs an interactive terminal")
+			}
+		default:
+			return fmt.Errorf("invalid --on-conflict %q: use skip, overwrite, rename or prompt", mergeOnConflict)
+		}
+
+		if sameFile(srcPath, dstPath) {
+			return fmt.Errorf("source and destination are the same file")
+		}
+
+		src, err := openCodeplugReadOnly(srcPath)
+		if err != nil {
+			return fmt.Errorf("failed to open codeplug %s: %w", srcPath, err)
+		}
+		defer src.Close()
+
+		srcModel, err := src.Model()
+		if err != nil {
+			return err
+		}
+		srcChannels, err := src.GetChannels()
+		if err != nil {
+			return fmt.Errorf("failed to get channels from %s: %w", srcPath, err)
+		}
+
+		dst, err := openCodeplug(dstPath)
+		if err != nil {
+			return fmt.Errorf("failed to open codeplug %s: %w", dstPath, err)
+		}
+		defer dst.Close()
+
+		// Every change is made to an in-memory copy and written back in one
+		// go, so a failure part way through leaves the destination untouched.
+		original, err := dst.Snapshot()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dstPath, err)
+		}
+		buf := codeplug.NewFromBytes(original)
+
+		summary, err := mergeChannels(src, srcModel, srcChannels, buf)
+		if err != nil {
+			return fmt.Errorf("merge aborted, %s was not changed: %w", dstPath, err)
+		}
+
+		if summary.added+summary.overwritten+summary.renamed > 0 {
+			merged, err := buf.Snapshot()
+			if err != nil {
+				return err
+			}
+
+			backupPath, err := dst.Backup()
+			if err != nil {
+				return fmt.Errorf("failed to back up codeplug: %w", err)
+			}
+			fmt.Printf("Backup written to %s\n", backupPath)
+
+			if err := dst.Restore(merged); err != nil {
+				return fmt.Errorf("failed to write merged codeplug: %w", err)
+			}
+		}
+
+		fmt.Printf("Added %d, overwritten %d, renamed %d, skipped %d, identical %d\n",
+			summary.added, summary.overwritten, summary.renamed, summary.skipped, summary.identical)
+		return nil
+	},
+}
+
+func mergeChannels(src *codeplug.Codeplug, srcModel codeplug.Model, srcChannels []*codeplug.Channel, dst *codeplug.Codeplug) (mergeSummary, error) {
+	var summary mergeSummary
+
+	dstModel, err := dst.Model()
+	if err != nil {
+		return summary, err
+	}
+	if srcModel.Name != dstModel.Name {
+		fmt.Fprintf(os.Stderr, "warning: merging from %s to %s; fields this tool does not decode are copied unchanged\n", srcModel.Name, dstModel.Name)
+	}
+
+	dstChannels, err := dst.GetChannels()
+	if err != nil {
+		return summary, fmt.Errorf("failed to get channels: %w", err)
+	}
+	byName := make(map[string]*codeplug.Channel, len(dstChannels))
+	for _, channel := range dstChannels {
+		byName[channel.Name] = channel
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for i, channel := range srcChannels {
+		prefix := fmt.Sprintf("[%d/%d]", i+1, len(srcChannels))
+
+		record, err := src.ReadChannelRecord(channel)
+		if err != nil {
+			return summary, fmt.Errorf("failed to read channel %d: %w", channel.Index, err)
+		}
+
+		existing, ok := byName[channel.Name]
+		if !ok {
+			at, err := dst.NextFreeChannelIndex()
+			if err != nil {
+				return summary, err
+			}
+			if err := dst.InsertChannelRecord(at, record, srcModel); err != nil {
+				return summary, fmt.Errorf("failed to add %s: %w", channel.Name, err)
+			}
+			byName[channel.Name] = &codeplug.Channel{Index: at, Name: channel.Name, RxFreq: channel.RxFreq, TxFreq: channel.TxFreq}
+			fmt.Printf("%s + %s\n", prefix, channel.Name)
+			summary.added++
+			continue
+		}
+
+		if existing.RxFreq == channel.RxFreq && existing.TxFreq == channel.TxFreq {
+			fmt.Printf("%s = %s\n", prefix, channel.Name)
+			summary.identical++
+			continue
+		}
+
+		strategy := mergeOnConflict
+		if strategy == conflictPrompt {
+			strategy = promptConflict(reader, existing, channel)
+		}
+
+		switch strategy {
+		case conflictOverwrite:
+			if err := dst.ReplaceChannelRecord(existing.Index, record, srcModel); err != nil {
+				return summary, fmt.Errorf("failed to overwrite %s: %w", channel.Name, err)
+			}
+			existing.RxFreq, existing.TxFreq = channel.RxFreq, channel.TxFreq
+			fmt.Printf("%s ~ %s (overwritten)\n", prefix, channel.Name)
+			summary.overwritten++
+		case conflictRename:
+			name := uniqueChannelName(channel.Name, dstModel.MaxChannelNameLength, byName)
+			renamed, err := codeplug.ChannelRecordWithName(record, name)
+			if err != nil {
+				return summary, err
+			}
+			at, err := dst.NextFreeChannelIndex()
+			if err != nil {
+				return summary, err
+			}
+			if err := dst.InsertChannelRecord(at, renamed, srcModel); err != nil {
+				return summary, fmt.Errorf("failed to add %s: %w", name, err)
+			}
+			byName[name] = &codeplug.Channel{Index: at, Name: name, RxFreq: channel.RxFreq, TxFreq: channel.TxFreq}
+			fmt.Printf("%s + %s (renamed from %s)\n", prefix, name, channel.Name)
+			summary.renamed++
+		default:
+			fmt.Printf("%s ! %s (skipped, conflicts with channel %d)\n", prefix, channel.Name, existing.Index)
+			summary.skipped++
+		}
+	}
+
+	return summary, nil
+}
+
+func promptConflict(reader *bufio.Reader, existing, incoming *codeplug.Channel) string {
+	fmt.Printf("%s is %s/%s MHz here and %s/%s MHz in the source.\n", existing.Name,
+		formatMHz(int64(existing.RxFreq)), formatMHz(int64(existing.TxFreq)),
+		formatMHz(int64(incoming.RxFreq)), formatMHz(int64(incoming.TxFreq)))
+	for {
+		fmt.Print("[s]kip, [o]verwrite or [r]ename? ")
+		answer, err := reader.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "s", "skip":
+			return conflictSkip
+		case "o", "overwrite":
+			return conflictOverwrite
+		case "r", "rename":
+			return conflictRename
+		}
+		if err != nil {
+			return conflictSkip
+		}
+	}
+}
+
+// uniqueChannelName appends -2, -3, ... to name until it is not taken,
+// shortening name so the result still fits in maxLength characters.
+func uniqueChannelName(name string, maxLength int, taken map[string]*codeplug.Channel) string {
+	for n := 2; ; n++ {
+		suffix := "-" + strconv.Itoa(n)
+		base := name
+		if len(base)+len(suffix) > maxLength {
+			base = base[:max(0, maxLength-len(suffix))]
+		}
+		if _, ok := taken[base+suffix]; !ok {
+			return base + suffix
+		}
+	}
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&mergeOnConflict, "on-conflict", conflictSkip, "What to do when a channel name exists with different frequencies (skip, overwrite, rename, prompt)")
+}
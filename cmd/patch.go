@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var patchForce bool
+
+var patchCmd = &cobra.Command{
+	Use:   "patch <offset> <hexbytes>",
+	Short: "Write raw bytes at an absolute offset (dangerous)",
+	Long: `Write raw bytes at an absolute file offset.
+
+This bypasses every check the tool normally performs and can easily corrupt a
+codeplug. It is intended for experts applying known edits the tool does not
+model yet. A timestamped backup is written next to the file before patching,
+and --force is required.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		offset, err := strconv.ParseInt(args[0], 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset: %w", err)
+		}
+
+		data, err := hex.DecodeString(strings.ReplaceAll(args[1], " ", ""))
+		if err != nil {
+			return fmt.Errorf("invalid hex bytes: %w", err)
+		}
+
+		if !patchForce {
+			return fmt.Errorf("refusing to patch without --force")
+		}
+
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			size, err := cp.Size()
+			if err != nil {
+				return err
+			}
+			if offset < 0 || offset+int64(len(data)) > size {
+				return fmt.Errorf("range 0x%X+%d is outside the file (size %d)", offset, len(data), size)
+			}
+
+			backupPath, err := cp.Backup()
+			if err != nil {
+				return fmt.Errorf("failed to back up codeplug: %w", err)
+			}
+			fmt.Printf("Backup written to %s\n", backupPath)
+
+			if err := cp.WriteRaw(offset, data); err != nil {
+				return fmt.Errorf("failed to patch codeplug: %w", err)
+			}
+
+			fmt.Printf("Successfully wrote %d bytes at offset 0x%X\n", len(data), offset)
+			return nil
+		})
+	},
+}
+
+func init() {
+	patchCmd.Flags().BoolVar(&patchForce, "force", false, "Confirm the raw write")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var readCmd = &cobra.Command{
+	Use:   "read <offset> <length>",
+	Short: "Print a hex and ASCII dump of a byte range",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		offset, err := strconv.ParseInt(args[0], 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset: %w", err)
+		}
+
+		length, err := strconv.ParseInt(args[1], 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid length: %w", err)
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			data, err := cp.ReadRaw(offset, int(length))
+			if err != nil {
+				return fmt.Errorf("failed to read codeplug: %w", err)
+			}
+
+			hexDump(os.Stdout, offset, data)
+			return nil
+		})
+	},
+}
+
+func hexDump(w io.Writer, offset int64, data []byte) {
+	const width = 16
+	for start := 0; start < len(data); start += width {
+		end := start + width
+		if end > len(data) {
+			end = len(data)
+		}
+		line := data[start:end]
+
+		var hexPart, asciiPart strings.Builder
+		for i := 0; i < width; i++ {
+			if i == width/2 {
+				hexPart.WriteByte(' ')
+			}
+			if i < len(line) {
+				fmt.Fprintf(&hexPart, "%02x ", line[i])
+			} else {
+				hexPart.WriteString("   ")
+			}
+		}
+		for _, b := range line {
+			if b >= 0x20 && b < 0x7F {
+				asciiPart.WriteByte(b)
+			} else {
+				asciiPart.WriteByte('.')
+			}
+		}
+
+		fmt.Fprintf(w, "%08x  %s |%s|\n", offset+int64(start), hexPart.String(), asciiPart.String())
+	}
+}
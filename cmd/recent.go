@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var noRemember bool
+
+func recentFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "anytone-cli", "last-file"), nil
+}
+
+// interactiveSession reports whether both stdin and stdout are terminals.
+// The last-used file is neither remembered nor offered otherwise, so
+// scripts behave the same regardless of what ran before them.
+func interactiveSession() bool {
+	if noRemember || !stdinIsTerminal() {
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+func rememberFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	statePath, err := recentFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(statePath, []byte(abs+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to remember last file: %w", err)
+	}
+	return nil
+}
+
+// offerRecentFile asks whether to reuse the last codeplug opened in an
+// interactive session. It returns "" if there is none or the answer is no.
+func offerRecentFile() string {
+	if !interactiveSession() {
+		return ""
+	}
+
+	statePath, err := recentFilePath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "warning: failed to read last file: %v\n", err)
+		}
+		return ""
+	}
+
+	path := strings.TrimSpace(string(data))
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+
+	if !confirmPrompt(bufio.NewReader(os.Stdin), fmt.Sprintf("No codeplug given. Use %s?", path)) {
+		return ""
+	}
+	return path
+}
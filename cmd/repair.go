@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Repair inconsistencies in the codeplug",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+var repairRadioIDsCmd = &cobra.Command{
+	Use:   "radio-ids",
+	Short: "Rewrite radio IDs sorted by index",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			reordered, err := cp.RepairRadioIDs()
+			if err != nil {
+				return fmt.Errorf("failed to repair radio IDs: %w", err)
+			}
+
+			if reordered {
+				fmt.Println("Radio IDs were out of order and have been rewritten sorted by index")
+			} else {
+				fmt.Println("Radio IDs are already in order; no changes made")
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	repairCmd.AddCommand(repairRadioIDsCmd)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var reportCountOnly bool
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize channel settings worth reviewing",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+var reportDataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "List digital channels with non-default SMS and data settings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+
+			type match struct {
+				channel *codeplug.Channel
+				flags   []string
+			}
+			var matches []match
+			for _, channel := range channels {
+				if channel.ChannelType == codeplug.ChannelTypeAnalog {
+					continue
+				}
+
+				var flags []string
+				if channel.SmsConfirmation != 0 {
+					flags = append(flags, "sms-confirmation")
+				}
+				if channel.SmsForbid != 0 {
+					flags = append(flags, "sms-forbid")
+				}
+				if channel.DataAckDisable != 0 {
+					flags = append(flags, "data-ack-disable")
+				}
+				if len(flags) == 0 {
+					continue
+				}
+
+				matches = append(matches, match{channel: channel, flags: flags})
+			}
+
+			if reportCountOnly {
+				fmt.Println(len(matches))
+				return nil
+			}
+
+			for _, m := range matches {
+				fmt.Printf("%d: %s (%s)\n", m.channel.Index, m.channel.Name, strings.Join(m.flags, ", "))
+			}
+			if len(matches) == 0 {
+				fmt.Println("All digital channels use the default SMS and data settings")
+			}
+			return nil
+		})
+	},
+}
+
+var reportEncryptionCmd = &cobra.Command{
+	Use:   "encryption",
+	Short: "List channels with encryption enabled and their key mode",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+
+			var matches []*codeplug.Channel
+			for _, channel := range channels {
+				if channel.AesEncryptionKey != 0 || channel.MultipleKey != 0 || channel.RandomKey != 0 {
+					matches = append(matches, channel)
+				}
+			}
+
+			if reportCountOnly {
+				fmt.Println(len(matches))
+				return nil
+			}
+
+			for _, channel := range matches {
+				fmt.Printf("%d: %s (%s)\n", channel.Index, channel.Name, channel.EncryptionMode())
+			}
+			if len(matches) == 0 {
+				fmt.Println("No channels use encryption")
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	reportCmd.PersistentFlags().BoolVar(&reportCountOnly, "count-only", false, "Print only the number of matching records")
+
+	reportCmd.AddCommand(reportDataCmd)
+	reportCmd.AddCommand(reportEncryptionCmd)
+}
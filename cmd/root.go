@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
-var codeplugFile string
+var (
+	codeplugFile string
+	verifyWrites bool
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "anytone-cli",
@@ -14,6 +18,14 @@ var rootCmd = &cobra.Command{
 	Long: `A command-line interface for working with Anytone codeplugs.
 This tool allows you to view and modify parameters in Anytone radio codeplug (.rdt) files
 without using the official CPS software.`,
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if codeplugFile == "" || !interactiveSession() {
+			return
+		}
+		if err := rememberFile(codeplugFile); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	},
 }
 
 func Execute() error {
@@ -36,7 +48,7 @@ func Execute() error {
 }
 
 func isCommand(cmd string) bool {
-	commands := []string{"help", "completion", "info", "set", "get"}
+	commands := []string{"help", "completion", "info", "set", "get", "diff", "run", "patch", "read", "check-writable", "repair", "version", "find", "validate", "dump", "swap", "browse", "clear", "report", "layout", "copy-channel", "capacity", "merge", "export", "import", "template"}
 	for _, c := range commands {
 		if c == cmd {
 			return true
@@ -46,7 +58,46 @@ func isCommand(cmd string) bool {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", formatText, "Output format (text, json, jsonl)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", formatText, "Output format (same as --format)")
+	rootCmd.PersistentFlags().BoolVar(&compactJSON, "compact", false, "Print JSON on a single line instead of indented")
+	rootCmd.PersistentFlags().StringVar(&globPattern, "glob", "", "Run info across every codeplug matching a glob pattern")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", errorFormatText, "Error output format on stderr (text, json)")
+	rootCmd.PersistentFlags().BoolVar(&verifyWrites, "verify-writes", false, "Read back every write and fail if the bytes on disk differ")
+	rootCmd.PersistentFlags().BoolVar(&noRemember, "no-remember", false, "Do not remember or offer the last codeplug file")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+
+	// Errors are reported by ReportError so JSON errors are not preceded by
+	// cobra's plain-text copy.
+	cobra.OnInitialize(func() {
+		if errorFormat == errorFormatJSON {
+			rootCmd.SilenceErrors = true
+			rootCmd.SilenceUsage = true
+		}
+	})
+
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(setRadioCmd)
 	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(patchCmd)
+	rootCmd.AddCommand(readCmd)
+	rootCmd.AddCommand(checkWritableCmd)
+	rootCmd.AddCommand(repairCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(findCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(swapCmd)
+	rootCmd.AddCommand(browseCmd)
+	rootCmd.AddCommand(clearCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(layoutCmd)
+	rootCmd.AddCommand(copyChannelCmd)
+	rootCmd.AddCommand(capacityCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(templateCmd)
 }
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type scriptCommand struct {
+	minArgs int
+	maxArgs int
+	run     func(cp *codeplug.Codeplug, args []string) error
+	// flags are the command's flags, reset to their defaults before every
+	// line so a flag given on one line does not carry over to the next.
+	flags *pflag.FlagSet
+}
+
+var scriptCommands = map[string]scriptCommand{
+	"set radio_id": {minArgs: 2, maxArgs: 2, run: setRadioID},
+	"set channel":  {minArgs: 2, maxArgs: 6, run: setChannelField, flags: setChannelFlags},
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run <script>",
+	Short: "Run commands from a script file, one per line",
+	Long: `Run commands from a script file against the codeplug, one command per line.
+Blank lines and lines starting with # are ignored. Execution stops at the first
+failing line and every change made by the script is rolled back.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		script, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open script: %w", err)
+		}
+		defer script.Close()
+
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			original, err := cp.Snapshot()
+			if err != nil {
+				return fmt.Errorf("failed to snapshot codeplug: %w", err)
+			}
+
+			if err := runScript(cp, script); err != nil {
+				if restoreErr := cp.Restore(original); restoreErr != nil {
+					return fmt.Errorf("%w (rollback failed: %v)", err, restoreErr)
+				}
+				return fmt.Errorf("%w (no changes were saved)", err)
+			}
+
+			return nil
+		})
+	},
+}
+
+func runScript(cp *codeplug.Codeplug, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields, err := splitScriptLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		if err := runScriptLine(cp, fields); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+
+	return nil
+}
+
+func runScriptLine(cp *codeplug.Codeplug, fields []string) error {
+	if len(fields) < 2 {
+		return fmt.Errorf("unknown command: %s", strings.Join(fields, " "))
+	}
+
+	name := fields[0] + " " + fields[1]
+	command, ok := scriptCommands[name]
+	if !ok {
+		return fmt.Errorf("unknown command: %s", name)
+	}
+
+	args := fields[2:]
+	if len(args) < command.minArgs || len(args) > command.maxArgs {
+		return fmt.Errorf("%s: expected %d to %d arguments, got %d", name, command.minArgs, command.maxArgs, len(args))
+	}
+
+	if command.flags != nil {
+		if err := resetFlags(command.flags); err != nil {
+			return err
+		}
+	}
+
+	return command.run(cp, args)
+}
+
+func resetFlags(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if setErr := f.Value.Set(f.DefValue); setErr != nil && err == nil {
+			err = fmt.Errorf("failed to reset --%s: %w", f.Name, setErr)
+		}
+		f.Changed = false
+	})
+	return err
+}
+
+func splitScriptLine(line string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	inQuotes := false
+	inField := false
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inField = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+
+	return fields, nil
+}
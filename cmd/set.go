@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"log"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/emerson000/anytone-cli/pkg/codeplug"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var setRadioCmd = &cobra.Command{
@@ -24,31 +29,548 @@ var setRadioIDCmd = &cobra.Command{
 	Short: "Update a radio ID",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		index, err := strconv.Atoi(args[0])
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			return setRadioID(cp, args)
+		})
+	},
+}
+
+func setRadioID(cp *codeplug.Codeplug, args []string) error {
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid index: %w", err)
+	}
+
+	newID, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid radio ID: %w", err)
+	}
+
+	if err := cp.UpdateRadioID(index, newID); err != nil {
+		return fmt.Errorf("failed to update radio ID: %w", err)
+	}
+
+	fmt.Printf("Successfully updated radio ID at index %d to %d\n", index, newID)
+	return nil
+}
+
+type channelSetter func(cp *codeplug.Codeplug, index int, value string) error
+
+var channelSetters = map[string]channelSetter{
+	"correct-freq": func(cp *codeplug.Codeplug, index int, value string) error {
+		correctFreq, err := strconv.Atoi(value)
 		if err != nil {
-			return fmt.Errorf("invalid index: %w", err)
+			return fmt.Errorf("invalid correct frequency: %w", err)
+		}
+		return cp.SetChannelCorrectFreq(index, correctFreq)
+	},
+	"tx-direction": func(cp *codeplug.Codeplug, index int, value string) error {
+		switch value {
+		case "simplex":
+			return cp.SetChannelTxDirection(index, codeplug.TxDirectionSimplex, 0)
+		case "independent":
+			return cp.SetChannelTxDirection(index, codeplug.TxDirectionIndependent, 0)
+		}
+
+		direction := codeplug.TxDirectionPlus
+		if strings.HasPrefix(value, "-") {
+			direction = codeplug.TxDirectionMinus
+		} else if !strings.HasPrefix(value, "+") {
+			return fmt.Errorf("invalid tx direction %q: use simplex, independent, +MHZ or -MHZ", value)
 		}
 
-		newID, err := strconv.Atoi(args[1])
+		offset, err := parseMHz(value[1:])
+		if err != nil {
+			return fmt.Errorf("invalid offset: %w", err)
+		}
+		return cp.SetChannelTxDirection(index, direction, offset)
+	},
+	"aprs-rx": func(cp *codeplug.Codeplug, index int, value string) error {
+		on, err := parseOnOff(value)
+		if err != nil {
+			return err
+		}
+		return cp.SetChannelAprsRx(index, on)
+	},
+	"name": func(cp *codeplug.Codeplug, index int, value string) error {
+		if setChannelUnique {
+			if err := checkUniqueChannelName(cp, index, value); err != nil {
+				return err
+			}
+		}
+		return cp.SetChannelName(index, value)
+	},
+	"encryption-key": func(cp *codeplug.Codeplug, index int, value string) error {
+		key := 0
+		if value != "off" {
+			var err error
+			key, err = strconv.Atoi(value)
+			if err != nil || key < 1 || key > 255 {
+				return fmt.Errorf("invalid encryption key %q: use off or 1-255", value)
+			}
+		}
+		return setChannelEncryption(cp, index, func(c *codeplug.Channel) { c.AesEncryptionKey = byte(key) })
+	},
+	"multiple-key": func(cp *codeplug.Codeplug, index int, value string) error {
+		on, err := parseOnOff(value)
+		if err != nil {
+			return err
+		}
+		return setChannelEncryption(cp, index, func(c *codeplug.Channel) { c.MultipleKey = boolByte(on) })
+	},
+	"random-key": func(cp *codeplug.Codeplug, index int, value string) error {
+		on, err := parseOnOff(value)
 		if err != nil {
-			return fmt.Errorf("invalid radio ID: %w", err)
+			return err
+		}
+		return setChannelEncryption(cp, index, func(c *codeplug.Channel) { c.RandomKey = boolByte(on) })
+	},
+	"rx-tone": func(cp *codeplug.Codeplug, index int, value string) error {
+		return cp.SetChannelRxTone(index, value)
+	},
+	"tx-tone": func(cp *codeplug.Codeplug, index int, value string) error {
+		return cp.SetChannelTxTone(index, value)
+	},
+	"type": func(cp *codeplug.Codeplug, index int, value string) error {
+		channelType, err := codeplug.ParseChannelType(value)
+		if err != nil {
+			return err
 		}
 
-		cp, err := codeplug.Open(codeplugFile)
+		narrowed, err := cp.SetChannelType(index, channelType, !setChannelKeepBandwidth)
 		if err != nil {
-			return fmt.Errorf("failed to open codeplug: %w", err)
+			return err
+		}
+		if narrowed {
+			fmt.Fprintf(os.Stderr, "note: bandwidth of channel %d set to 12.5 kHz, the only width DMR allows\n", index)
+		}
+		return nil
+	},
+}
+
+// setChannelEncryption applies one change to the channel's encryption
+// settings and writes them back together, so the combination is validated.
+func setChannelEncryption(cp *codeplug.Codeplug, index int, change func(c *codeplug.Channel)) error {
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+	change(channel)
+	return cp.SetChannelEncryption(index, channel.AesEncryptionKey, channel.MultipleKey != 0, channel.RandomKey != 0)
+}
+
+func parseOnOff(value string) (bool, error) {
+	switch value {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q: use on or off", value)
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func parseMHz(value string) (uint32, error) {
+	mhz, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return codeplug.MHzToFreq(mhz)
+}
+
+// materialChannelFields are the fields whose changes alter what the
+// channel transmits on, so `set channel` asks before writing them.
+var materialChannelFields = map[string]bool{
+	"tx-direction": true,
+	"type":         true,
+	"rx-tone":      true,
+	"tx-tone":      true,
+	"tone":         true,
+}
+
+var (
+	setChannelFlags         = pflag.NewFlagSet("channel", pflag.ContinueOnError)
+	setChannelForce         bool
+	setChannelYes           bool
+	setChannelConfirm       bool
+	setChannelKeepBandwidth bool
+	setChannelUnique        bool
+)
+
+// checkUniqueChannelName fails when a channel other than index already has
+// the name, naming that channel so the user can rename one of them.
+func checkUniqueChannelName(cp *codeplug.Codeplug, index int, name string) error {
+	channels, err := cp.GetChannels()
+	if err != nil {
+		return fmt.Errorf("failed to get channels: %w", err)
+	}
+	for _, channel := range channels {
+		if channel.Index != index && channel.Name == name {
+			return fmt.Errorf("channel %d is already named %q", channel.Index, name)
+		}
+	}
+	return nil
+}
+
+var channelActions = map[string]func(cp *codeplug.Codeplug, index int) error{
+	"reset": func(cp *codeplug.Codeplug, index int) error {
+		if !setChannelForce {
+			return fmt.Errorf("refusing to reset channel without --force")
+		}
+
+		backupPath, err := cp.Backup()
+		if err != nil {
+			return fmt.Errorf("failed to back up codeplug: %w", err)
+		}
+		fmt.Printf("Backup written to %s\n", backupPath)
+
+		return cp.ResetChannel(index)
+	},
+	"delete": func(cp *codeplug.Codeplug, index int) error {
+		if !setChannelForce {
+			return fmt.Errorf("refusing to delete channel without --force")
+		}
+
+		backupPath, err := cp.Backup()
+		if err != nil {
+			return fmt.Errorf("failed to back up codeplug: %w", err)
+		}
+		fmt.Printf("Backup written to %s\n", backupPath)
+
+		return cp.DeleteChannel(index)
+	},
+}
+
+var setChannelCmd = &cobra.Command{
+	Use:   "channel <field> <index> <value> | channel reset|delete <index> --force",
+	Short: "Update a channel field",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setChannelConfirm = true
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			return setChannelField(cp, args)
+		})
+	},
+}
+
+var (
+	setToneRx string
+	setToneTx string
+)
+
+// setChannelTones handles the tone field, which takes both tones from --rx
+// and --tx. Both are checked before either is written, then each goes
+// through the same setter as rx-tone and tx-tone.
+func setChannelTones(cp *codeplug.Codeplug, index int, _ string) error {
+	if setToneRx == "" && setToneTx == "" {
+		return fmt.Errorf("give --rx, --tx or both")
+	}
+	for _, tone := range []string{setToneRx, setToneTx} {
+		if tone == "" {
+			continue
 		}
-		defer cp.Close()
+		if _, _, err := codeplug.ParseTone(tone); err != nil {
+			return err
+		}
+	}
 
-		if err := cp.UpdateRadioID(index, newID); err != nil {
-			return fmt.Errorf("failed to update radio ID: %w", err)
+	if setToneRx != "" {
+		if err := channelSetters["rx-tone"](cp, index, setToneRx); err != nil {
+			return err
 		}
+	}
+	if setToneTx != "" {
+		return channelSetters["tx-tone"](cp, index, setToneTx)
+	}
+	return nil
+}
+
+func toneOrUnchanged(tone string) string {
+	if tone == "" {
+		return "unchanged"
+	}
+	return tone
+}
 
-		fmt.Printf("Successfully updated radio ID at index %d to %d\n", index, newID)
+func setChannelField(cp *codeplug.Codeplug, args []string) error {
+	args, flagArgs := splitLongFlags(setChannelFlags, args)
+	if err := setChannelFlags.Parse(flagArgs); err != nil {
+		return err
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("expected <field> <index> [value]")
+	}
+
+	// Both `<field> <index>` and `<index> <field>` are accepted; a field
+	// name is never a number, so the order is unambiguous.
+	field, indexArg := args[0], args[1]
+	if _, err := strconv.Atoi(args[0]); err == nil {
+		field, indexArg = args[1], args[0]
+	}
+	index, err := strconv.Atoi(indexArg)
+	if err != nil {
+		return fmt.Errorf("invalid index: %w", err)
+	}
+	if action, ok := channelActions[field]; ok {
+		if len(args) != 2 {
+			return fmt.Errorf("%s takes no value", field)
+		}
+		if err := action(cp, index); err != nil {
+			return fmt.Errorf("failed to %s channel: %w", field, err)
+		}
+		fmt.Printf("Successfully ran %s on channel %d\n", field, index)
 		return nil
+	}
+
+	setter, ok := channelSetters[field]
+	value := ""
+	switch {
+	case field == "tone":
+		if len(args) != 2 {
+			return fmt.Errorf("expected tone <index> --rx <tone> --tx <tone>")
+		}
+		setter, value = setChannelTones, fmt.Sprintf("rx %s, tx %s", toneOrUnchanged(setToneRx), toneOrUnchanged(setToneTx))
+	case !ok:
+		return fmt.Errorf("unknown channel field: %s", field)
+	case len(args) != 3:
+		return fmt.Errorf("expected %s <index> <value>", field)
+	default:
+		value = args[2]
+	}
+
+	if setChannelConfirm && !setChannelYes && materialChannelFields[field] {
+		confirmed, err := confirmChannelChange(cp, index, func() error { return setter(cp, index, value) })
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Cancelled; no changes were saved")
+			return nil
+		}
+	} else if err := setter(cp, index, value); err != nil {
+		return fmt.Errorf("failed to update channel: %w", err)
+	}
+
+	fmt.Printf("Successfully updated %s of channel %d to %s\n", field, index, value)
+	return nil
+}
+
+// confirmChannelChange applies change, shows the decoded fields it altered
+// and asks whether to keep it, restoring the file if the answer is no.
+func confirmChannelChange(cp *codeplug.Codeplug, index int, change func() error) (bool, error) {
+	if !stdinIsTerminal() {
+		return false, fmt.Errorf("refusing to change the channel without confirmation; pass --yes when not running interactively")
+	}
+
+	before, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return false, fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	original, err := cp.Snapshot()
+	if err != nil {
+		return false, fmt.Errorf("failed to snapshot codeplug: %w", err)
+	}
+
+	if err := change(); err != nil {
+		return false, fmt.Errorf("failed to update channel: %w", err)
+	}
+
+	after, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return false, fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	fmt.Printf("Channel %d: %s\n", index, before.Name)
+	for _, f := range codeplug.ChannelFields {
+		if old, new := f.Format(before), f.Format(after); old != new {
+			fmt.Printf("  %s: %s → %s\n", f.Name, old, new)
+		}
+	}
+
+	if confirmPrompt(bufio.NewReader(os.Stdin), "Apply this change?") {
+		return true, nil
+	}
+
+	if err := cp.Restore(original); err != nil {
+		return false, fmt.Errorf("failed to undo the change: %w", err)
+	}
+	return false, nil
+}
+
+func stdinIsTerminal() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+var (
+	setToneFrom int
+	setToneTo   int
+)
+
+var setChannelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "Update a range of channels",
+}
+
+var setChannelsToneCmd = &cobra.Command{
+	Use:   "tone <tone> --from <index> --to <index>",
+	Short: "Set the RX and TX tone of every analog channel in a range",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tone := args[0]
+		if _, _, err := codeplug.ParseTone(tone); err != nil {
+			return err
+		}
+		if setToneFrom < 0 || setToneTo < setToneFrom {
+			return fmt.Errorf("invalid range %d to %d", setToneFrom, setToneTo)
+		}
+
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			channels, err := cp.GetChannels()
+			if err != nil {
+				return fmt.Errorf("failed to get channels: %w", err)
+			}
+			if setToneTo >= len(channels) {
+				return fmt.Errorf("channel %d does not exist: the codeplug has %d channels", setToneTo, len(channels))
+			}
+
+			var targets []*codeplug.Channel
+			for _, channel := range channels[setToneFrom : setToneTo+1] {
+				if channel.IsDigital() {
+					fmt.Fprintf(os.Stderr, "warning: skipping digital channel %d (%s)\n", channel.Index, channel.Name)
+					continue
+				}
+				targets = append(targets, channel)
+			}
+			if len(targets) == 0 {
+				fmt.Println("No analog channels in range; nothing to change")
+				return nil
+			}
+
+			if !setChannelYes {
+				if !stdinIsTerminal() {
+					return fmt.Errorf("refusing to change %d channels without confirmation; pass --yes when not running interactively", len(targets))
+				}
+				for _, channel := range targets {
+					fmt.Printf("  %d: %s (rx %s, tx %s)\n", channel.Index, channel.Name, channel.DecodeRxTone(), channel.DecodeTxTone())
+				}
+				if !confirmPrompt(bufio.NewReader(os.Stdin), fmt.Sprintf("Set the tone of these %d channels to %s?", len(targets), tone)) {
+					fmt.Println("Cancelled; no changes were saved")
+					return nil
+				}
+			}
+
+			backupPath, err := cp.Backup()
+			if err != nil {
+				return fmt.Errorf("failed to back up codeplug: %w", err)
+			}
+			fmt.Printf("Backup written to %s\n", backupPath)
+
+			for _, channel := range targets {
+				if err := cp.SetChannelTones(channel.Index, tone, tone); err != nil {
+					return fmt.Errorf("failed to update channel %d: %w", channel.Index, err)
+				}
+			}
+
+			fmt.Printf("Successfully set the tone of %d channels to %s\n", len(targets), tone)
+			return nil
+		})
 	},
 }
 
+// splitLongFlags separates --flags from positional arguments. Channel field
+// values such as a negative offset start with "-", so only long flags are
+// treated as flags once positional arguments have started. A flag in flags
+// that takes a value consumes the next argument unless given as --flag=value.
+func splitLongFlags(flags *pflag.FlagSet, args []string) (positional, flagArgs []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+
+		flagArgs = append(flagArgs, arg)
+		name := strings.TrimPrefix(arg, "--")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := flags.Lookup(name); f != nil && f.Value.Type() != "bool" && i+1 < len(args) {
+			i++
+			flagArgs = append(flagArgs, args[i])
+		}
+	}
+	return positional, flagArgs
+}
+
+func withCodeplug(fn func(cp *codeplug.Codeplug) error) error {
+	cp, err := openCodeplug(codeplugFile)
+	if err != nil {
+		return fmt.Errorf("failed to open codeplug: %w", err)
+	}
+	defer cp.Close()
+
+	return fn(cp)
+}
+
+// withCodeplugReadOnly is withCodeplug for commands that only read, which
+// can run alongside each other.
+func withCodeplugReadOnly(fn func(cp *codeplug.Codeplug) error) error {
+	cp, err := openCodeplugReadOnly(codeplugFile)
+	if err != nil {
+		return fmt.Errorf("failed to open codeplug: %w", err)
+	}
+	defer cp.Close()
+
+	return fn(cp)
+}
+
+func openCodeplug(path string) (*codeplug.Codeplug, error) {
+	return configureCodeplug(codeplug.Open(path))
+}
+
+func openCodeplugReadOnly(path string) (*codeplug.Codeplug, error) {
+	return configureCodeplug(codeplug.OpenReadOnly(path))
+}
+
+func configureCodeplug(cp *codeplug.Codeplug, err error) (*codeplug.Codeplug, error) {
+	if err != nil {
+		return nil, err
+	}
+	cp.SetVerifyWrites(verifyWrites)
+	cp.SetLogger(log.New(os.Stderr, "warning: ", 0))
+	return cp, nil
+}
+
 func init() {
 	setRadioCmd.AddCommand(setRadioIDCmd)
+	setRadioCmd.AddCommand(setChannelCmd)
+
+	setChannelFlags.BoolVar(&setChannelForce, "force", false, "Confirm destructive channel actions such as reset and delete")
+	setChannelFlags.BoolVar(&setChannelYes, "yes", false, "Apply transmit-affecting changes without asking for confirmation")
+	setChannelFlags.BoolVar(&setChannelKeepBandwidth, "keep-bandwidth", false, "Do not narrow the bandwidth when switching a channel to digital")
+	setChannelFlags.BoolVar(&setChannelUnique, "unique", false, "Reject a new name that another channel already has")
+	setChannelFlags.StringVar(&setToneRx, "rx", "", "With the tone field: tone needed to open squelch, e.g. off, 100.0 or D023N")
+	setChannelFlags.StringVar(&setToneTx, "tx", "", "With the tone field: tone sent on transmit, in the same forms as --rx")
+	setChannelCmd.Flags().AddFlagSet(setChannelFlags)
+	setChannelCmd.Flags().SetInterspersed(false)
+
+	setRadioCmd.AddCommand(setChannelsCmd)
+	setChannelsCmd.AddCommand(setChannelsToneCmd)
+	setChannelsToneCmd.Flags().IntVar(&setToneFrom, "from", 0, "First channel index in the range")
+	setChannelsToneCmd.Flags().IntVar(&setToneTo, "to", 0, "Last channel index in the range, inclusive")
+	setChannelsToneCmd.Flags().BoolVar(&setChannelYes, "yes", false, "Apply the change without asking for confirmation")
+	setChannelsToneCmd.MarkFlagRequired("from")
+	setChannelsToneCmd.MarkFlagRequired("to")
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var swapCmd = &cobra.Command{
+	Use:   "swap",
+	Short: "Swap paired channel values",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+		return nil
+	},
+}
+
+var swapFreqCmd = &cobra.Command{
+	Use:   "freq <index>",
+	Short: "Swap a channel's RX and TX frequencies",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid index: %w", err)
+		}
+
+		return withCodeplug(func(cp *codeplug.Codeplug) error {
+			if err := cp.SwapChannelFrequencies(index); err != nil {
+				return fmt.Errorf("failed to swap frequencies: %w", err)
+			}
+
+			fmt.Printf("Successfully swapped RX and TX frequencies of channel %d\n", index)
+			return nil
+		})
+	},
+}
+
+func init() {
+	swapCmd.AddCommand(swapFreqCmd)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Print blank files to fill in and import",
+}
+
+var templateCSVCmd = &cobra.Command{
+	Use:   "csv",
+	Short: "Print the channel CSV header and an example row",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return codeplug.WriteChannelsCSVTemplate(os.Stdout)
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateCSVCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the codeplug for layout problems and misconfigurations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if codeplugFile == "" {
+			return fmt.Errorf("codeplug file path is required")
+		}
+
+		return withCodeplugReadOnly(func(cp *codeplug.Codeplug) error {
+			issues, err := cp.Validate()
+			if err != nil {
+				return fmt.Errorf("failed to validate codeplug: %w", err)
+			}
+
+			errors := 0
+			for _, issue := range issues {
+				color := colorYellow
+				if issue.Severity == codeplug.SeverityError {
+					color = colorRed
+					errors++
+				}
+				fmt.Printf("%s [%s] %s\n", colorize(color, issue.Severity.String()+":"), issue.Check, issue.Message)
+			}
+
+			if len(issues) == 0 {
+				fmt.Println(colorize(colorGreen, "No problems found"))
+			}
+			if errors > 0 {
+				return fmt.Errorf("validation found %d errors", errors)
+			}
+			return nil
+		})
+	},
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/emerson000/anytone-cli/pkg/codeplug"
+	"github.com/spf13/cobra"
+)
+
+// Set at build time with:
+//
+//	go build -ldflags "-X github.com/emerson000/anytone-cli/cmd.version=v1.2.3"
+var version = "dev"
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and supported radio models",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("anytone-cli %s (%s)\n", buildVersion(), runtime.Version())
+		fmt.Println("Supported models:")
+		for _, m := range codeplug.Models {
+			fmt.Printf("  %s (%s)\n", m.Name, m.ID)
+		}
+	},
+}
+
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return version
+}
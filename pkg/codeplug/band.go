@@ -0,0 +1,28 @@
+package codeplug
+
+type Band struct {
+	Name string
+	Low  uint32
+	High uint32
+}
+
+var Bands = []Band{
+	{Name: "10m", Low: mhz(28), High: mhz(29.7)},
+	{Name: "6m", Low: mhz(50), High: mhz(54)},
+	{Name: "2m", Low: mhz(144), High: mhz(148)},
+	{Name: "1.25m", Low: mhz(222), High: mhz(225)},
+	{Name: "70cm", Low: mhz(420), High: mhz(450)},
+	{Name: "33cm", Low: mhz(902), High: mhz(928)},
+	{Name: "23cm", Low: mhz(1240), High: mhz(1300)},
+}
+
+const OtherBand = "other"
+
+func BandName(freq uint32) string {
+	for _, b := range Bands {
+		if freq >= b.Low && freq <= b.High {
+			return b.Name
+		}
+	}
+	return OtherBand
+}
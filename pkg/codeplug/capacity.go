@@ -0,0 +1,33 @@
+package codeplug
+
+// SectionCapacity is how many records a section holds against the most the
+// radio accepts.
+type SectionCapacity struct {
+	Section string `json:"section"`
+	Used    int    `json:"used"`
+	Max     int    `json:"max"`
+}
+
+// Capacity reports usage for the sections the library can decode. Contacts,
+// zones and scan lists are not decoded yet and are not included.
+func (cp *Codeplug) Capacity() ([]SectionCapacity, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := cp.channelCount()
+	if err != nil {
+		return nil, err
+	}
+
+	radioIDs, err := cp.GetRadioIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	return []SectionCapacity{
+		{Section: "channels", Used: channels, Max: model.ChannelLimit()},
+		{Section: "radio IDs", Used: len(radioIDs), Max: maxRadioIDs},
+	}, nil
+}
@@ -1,67 +1,164 @@
 package codeplug
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
 )
 
-type Channel struct {
-	RxFreq               uint32
-	TxFreqDirection      byte
-	TxFreq               int32
-	ChannelType          byte
-	TxPower              byte
-	Bandwidth            byte
-	PttProhibit          byte
-	CallConfirmation     byte
-	TalkAround           byte
-	CtcssDcsDecode       byte
-	CtcssDcsDecodeOption byte
-	CtcssDcsEncode       byte
-	CtcssDcsEncodeOption byte
-	Contact              byte
-	RadioId              byte
-	TxPermit             byte
-	SquelchMode          byte
-	ScanList             int8
-	ReceiveGroupList     byte
-	RxColorCode          byte
-	Slot                 byte
-	SlotSuit             byte
-	AprsRx               byte
-	AesEncryptionKey     byte
-	WorkAlone            byte
-	Name                 string
-	Ranging              byte
-	CorrectFreq          int8
-	SmsConfirmation      byte
-	ExcludeFromRoaming   byte
-	MultipleKey          byte
-	RandomKey            byte
-	SmsForbid            byte
-	DataAckDisable       byte
-	AutoScan             byte
-	SendTalkerAlias      byte
-	ExtendEncryption     byte
-
-	NameOffset  int64
-	NameLength  int
-	TotalLength int
-}
+const (
+	channelHeaderSize   = 49
+	channelNameSize     = 32
+	channelTrailingSize = 27
 
-func (cp *Codeplug) readChannelMetadata(offset int64) (*Channel, error) {
-	adjustedOffset := offset
+	headerRxFreqOffset       = 3
+	headerTxDirectionOffset  = 7
+	headerTxFreqOffset       = 8
+	headerChannelTypeOffset  = 12
+	headerTxPowerOffset      = 13
+	headerBandwidthOffset    = 14
+	headerRxToneOffset       = 19
+	headerRxToneOptionOffset = 20
+	headerTxToneOffset       = 23
+	headerTxToneOptionOffset = 24
+	headerScanListOffset     = 35
+	headerColorCodeOffset    = 41
+	headerSlotOffset         = 42
+	headerAprsRxOffset       = 45
+	headerAesKeyOffset       = 46
 
-	const nameOffset = 49
-	header := make([]byte, nameOffset)
-	if _, err := cp.file.ReadAt(header, adjustedOffset); err != nil {
-		return nil, fmt.Errorf("failed to read channel header at offset %d: %w", adjustedOffset, err)
+	trailingCorrectFreqOffset     = 8
+	trailingSmsConfirmationOffset = 11
+	trailingMultipleKeyOffset     = 15
+	trailingRandomKeyOffset       = 16
+	trailingSmsForbidOffset       = 17
+	trailingDataAckDisableOffset  = 18
+
+	// trailingExtendEncryptionOffset is where the extend encryption flag was
+	// assumed to be, but it is past the end of the trailing bytes, so the
+	// field always reads 0. It is not shown until the real byte is found.
+	trailingExtendEncryptionOffset = 27
+)
+
+const (
+	TxDirectionSimplex byte = iota
+	TxDirectionPlus
+	TxDirectionMinus
+	TxDirectionIndependent
+)
+
+const (
+	ChannelTypeAnalog byte = iota
+	ChannelTypeDigital
+	ChannelTypeAnalogDigital
+	ChannelTypeDigitalAnalog
+)
+
+var ChannelTypeLabels = map[byte]string{
+	ChannelTypeAnalog:        "Analog",
+	ChannelTypeDigital:       "Digital",
+	ChannelTypeAnalogDigital: "A+D",
+	ChannelTypeDigitalAnalog: "D+A",
+}
+
+// ParseChannelType looks up a channel type by its label, ignoring case.
+func ParseChannelType(label string) (byte, error) {
+	for channelType, l := range ChannelTypeLabels {
+		if strings.EqualFold(label, l) {
+			return channelType, nil
+		}
 	}
+	return 0, fmt.Errorf("invalid channel type %q: use analog, digital, a+d or d+a", label)
+}
 
-	nameStartOffset := adjustedOffset + nameOffset
-	nameBuf := make([]byte, 32)
-	if _, err := cp.file.ReadAt(nameBuf, nameStartOffset); err != nil {
-		return nil, fmt.Errorf("failed to read channel name at offset %d: %w", nameStartOffset, err)
+const (
+	Bandwidth12_5 byte = iota
+	Bandwidth25
+)
+
+var BandwidthLabels = map[byte]string{
+	Bandwidth12_5: "12.5 kHz",
+	Bandwidth25:   "25 kHz",
+}
+
+const (
+	TxPowerLow byte = iota
+	TxPowerMid
+	TxPowerHigh
+	TxPowerTurbo
+)
+
+var TxPowerLabels = map[byte]string{
+	TxPowerLow:   "Low",
+	TxPowerMid:   "Mid",
+	TxPowerHigh:  "High",
+	TxPowerTurbo: "Turbo",
+}
+
+var TxDirectionLabels = map[byte]string{
+	TxDirectionSimplex:     "simplex",
+	TxDirectionPlus:        "+offset",
+	TxDirectionMinus:       "-offset",
+	TxDirectionIndependent: "independent",
+}
+
+type Channel struct {
+	RxFreq               uint32 `json:"rx_freq"`
+	TxFreqDirection      byte   `json:"tx_freq_direction"`
+	TxFreq               int32  `json:"tx_freq"`
+	ChannelType          byte   `json:"channel_type"`
+	TxPower              byte   `json:"tx_power"`
+	Bandwidth            byte   `json:"bandwidth"`
+	PttProhibit          byte   `json:"ptt_prohibit"`
+	CallConfirmation     byte   `json:"call_confirmation"`
+	TalkAround           byte   `json:"talk_around"`
+	CtcssDcsDecode       byte   `json:"ctcss_dcs_decode"`
+	CtcssDcsDecodeOption byte   `json:"ctcss_dcs_decode_option"`
+	CtcssDcsEncode       byte   `json:"ctcss_dcs_encode"`
+	CtcssDcsEncodeOption byte   `json:"ctcss_dcs_encode_option"`
+	Contact              byte   `json:"contact"`
+	RadioId              byte   `json:"radio_id"`
+	TxPermit             byte   `json:"tx_permit"`
+	SquelchMode          byte   `json:"squelch_mode"`
+	ScanList             int8   `json:"scan_list"`
+	ReceiveGroupList     byte   `json:"receive_group_list"`
+	RxColorCode          byte   `json:"rx_color_code"`
+	Slot                 byte   `json:"slot"`
+	SlotSuit             byte   `json:"slot_suit"`
+	AprsRx               byte   `json:"aprs_rx"`
+	AesEncryptionKey     byte   `json:"aes_encryption_key"`
+	WorkAlone            byte   `json:"work_alone"`
+	Name                 string `json:"name"`
+	Ranging              byte   `json:"ranging"`
+	CorrectFreq          int8   `json:"correct_freq"`
+	SmsConfirmation      byte   `json:"sms_confirmation"`
+	ExcludeFromRoaming   byte   `json:"exclude_from_roaming"`
+	MultipleKey          byte   `json:"multiple_key"`
+	RandomKey            byte   `json:"random_key"`
+	SmsForbid            byte   `json:"sms_forbid"`
+	DataAckDisable       byte   `json:"data_ack_disable"`
+	AutoScan             byte   `json:"auto_scan"`
+	SendTalkerAlias      byte   `json:"send_talker_alias"`
+	ExtendEncryption     byte   `json:"extend_encryption"`
+
+	Truncated bool `json:"truncated,omitempty"`
+
+	Index       int   `json:"index"`
+	Offset      int64 `json:"-"`
+	NameOffset  int64 `json:"-"`
+	NameLength  int   `json:"-"`
+	TotalLength int   `json:"-"`
+}
+
+func (cp *Codeplug) readChannelName(offset int64) (string, int, error) {
+	nameBuf := make([]byte, channelNameSize)
+	n, err := cp.readUpTo(nameBuf, offset)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read channel name at offset %d: %w", offset, err)
 	}
+	nameBuf = nameBuf[:n]
 
 	nameLength := 0
 	for i := 0; i < len(nameBuf); i++ {
@@ -72,58 +169,103 @@ func (cp *Codeplug) readChannelMetadata(offset int64) (*Channel, error) {
 	}
 
 	if nameLength == 0 {
-		return nil, fmt.Errorf("invalid channel name at offset %d: no null terminator found", nameStartOffset)
+		return "", 0, &ParseError{Offset: offset, Reason: "invalid channel name: no null terminator found"}
+	}
+
+	return string(nameBuf[:nameLength-1]), nameLength, nil
+}
+
+func (cp *Codeplug) readChannelMetadata(offset int64) (*Channel, error) {
+	adjustedOffset := offset
+
+	const nameOffset = channelHeaderSize
+	header := make([]byte, nameOffset)
+	if err := cp.readAt(header, adjustedOffset); err != nil {
+		return nil, fmt.Errorf("failed to read channel header at offset %d: %w", adjustedOffset, err)
+	}
+
+	nameStartOffset := adjustedOffset + nameOffset
+	name, nameLength, err := cp.readChannelName(nameStartOffset)
+	if err != nil {
+		return nil, err
 	}
 
 	trailingFieldsOffset := nameStartOffset + int64(nameLength)
-	trailingFields := make([]byte, 27)
+	trailingFields := make([]byte, channelTrailingSize)
 
-	if _, err := cp.file.ReadAt(trailingFields, trailingFieldsOffset); err != nil {
+	// A record cut off by the end of the file is decoded with the missing
+	// trailing bytes read as zero, so the rest of the walk still works.
+	n, err := cp.readUpTo(trailingFields, trailingFieldsOffset)
+	if err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("failed to read trailing fields at offset %d: %w", trailingFieldsOffset, err)
 	}
+	truncated := n < channelTrailingSize
+	if truncated {
+		cp.logf("channel record at offset %d is truncated: only %d of %d trailing bytes are present", offset, n, channelTrailingSize)
+	}
 
+	// The name buffer bounds the record. A name longer than the model
+	// displays is still read, so validate can report it, rather than
+	// stopping the walk here.
 	totalLength := nameOffset + nameLength + len(trailingFields)
 
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	rxFreq, err := model.FreqEncoding.Decode(header[headerRxFreqOffset:])
+	if err != nil {
+		return nil, &ParseError{Offset: offset + headerRxFreqOffset, Reason: fmt.Sprintf("invalid rx frequency: %v", err)}
+	}
+	txFreq, err := model.FreqEncoding.Decode(header[headerTxFreqOffset:])
+	if err != nil {
+		return nil, &ParseError{Offset: offset + headerTxFreqOffset, Reason: fmt.Sprintf("invalid tx frequency: %v", err)}
+	}
+
 	channel := &Channel{
-		RxFreq:               uint32(header[3]) | uint32(header[4])<<8 | uint32(header[5])<<16 | uint32(header[6])<<24,
-		TxFreqDirection:      header[7],
-		TxFreq:               int32(header[8]) | int32(header[9])<<8 | int32(header[10])<<16 | int32(header[11])<<24,
-		ChannelType:          header[12],
-		TxPower:              header[13],
-		Bandwidth:            header[14],
+		RxFreq:               rxFreq,
+		TxFreqDirection:      header[headerTxDirectionOffset],
+		TxFreq:               int32(txFreq),
+		ChannelType:          header[headerChannelTypeOffset],
+		TxPower:              header[headerTxPowerOffset],
+		Bandwidth:            header[headerBandwidthOffset],
 		PttProhibit:          header[16],
 		CallConfirmation:     header[17],
 		TalkAround:           header[18],
-		CtcssDcsDecode:       header[19],
-		CtcssDcsDecodeOption: header[20],
-		CtcssDcsEncode:       header[23],
-		CtcssDcsEncodeOption: header[24],
+		CtcssDcsDecode:       header[headerRxToneOffset],
+		CtcssDcsDecodeOption: header[headerRxToneOptionOffset],
+		CtcssDcsEncode:       header[headerTxToneOffset],
+		CtcssDcsEncodeOption: header[headerTxToneOptionOffset],
 		Contact:              header[29],
 		RadioId:              header[31],
 		TxPermit:             header[33],
 		SquelchMode:          header[34],
-		ScanList:             int8(header[35]),
+		ScanList:             int8(header[headerScanListOffset]),
 		ReceiveGroupList:     header[36],
-		RxColorCode:          header[41],
-		Slot:                 header[42],
+		RxColorCode:          header[headerColorCodeOffset],
+		Slot:                 header[headerSlotOffset],
 		SlotSuit:             header[44],
-		AprsRx:               header[45],
-		AesEncryptionKey:     header[46],
+		AprsRx:               header[headerAprsRxOffset],
+		AesEncryptionKey:     header[headerAesKeyOffset],
 		WorkAlone:            header[47],
-		Name:                 string(nameBuf[:nameLength-1]),
+		Name:                 name,
 
 		Ranging:            trailingFields[2],
-		CorrectFreq:        int8(trailingFields[8]),
-		SmsConfirmation:    trailingFields[11],
+		CorrectFreq:        int8(trailingFields[trailingCorrectFreqOffset]),
+		SmsConfirmation:    trailingFields[trailingSmsConfirmationOffset],
 		ExcludeFromRoaming: trailingFields[12],
-		MultipleKey:        trailingFields[15],
-		RandomKey:          trailingFields[16],
-		SmsForbid:          trailingFields[17],
-		DataAckDisable:     trailingFields[18],
+		MultipleKey:        trailingFields[trailingMultipleKeyOffset],
+		RandomKey:          trailingFields[trailingRandomKeyOffset],
+		SmsForbid:          trailingFields[trailingSmsForbidOffset],
+		DataAckDisable:     trailingFields[trailingDataAckDisableOffset],
 		AutoScan:           trailingFields[21],
 		SendTalkerAlias:    getSafeByteValue(trailingFields, 22),
-		ExtendEncryption:   getSafeByteValue(trailingFields, 27),
+		ExtendEncryption:   getSafeByteValue(trailingFields, trailingExtendEncryptionOffset),
+
+		Truncated: truncated,
 
+		Offset:      adjustedOffset,
 		NameOffset:  nameStartOffset,
 		NameLength:  nameLength,
 		TotalLength: totalLength,
@@ -133,49 +275,695 @@ func (cp *Codeplug) readChannelMetadata(offset int64) (*Channel, error) {
 }
 
 func (cp *Codeplug) GetChannels() ([]*Channel, error) {
-	channelCountBuf := make([]byte, 1)
-	if _, err := cp.file.ReadAt(channelCountBuf, totalChannelsAddress); err != nil {
-		return nil, fmt.Errorf("failed to read total channels: %w", err)
+	var channels []*Channel
+	err := cp.ForEachChannel(func(channel *Channel) error {
+		channels = append(channels, channel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	totalChannels := int(channelCountBuf[0])
-	channelsStartOffset := int64(totalChannelsAddress + 1)
-	currentOffset := channelsStartOffset
-	channels := make([]*Channel, 0, totalChannels)
+	return channels, nil
+}
+
+// channelsStartOffset returns where the model's channel records begin. If
+// the file has channels, the first record must decode there; otherwise the
+// file does not use the layout the model describes. Field values are not
+// checked so that a single corrupt channel can still be read and reset.
+func (cp *Codeplug) channelsStartOffset() (int64, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := cp.channelCount()
+	if err != nil || count == 0 {
+		return model.ChannelsOffset, err
+	}
+
+	if _, err := cp.readChannelMetadata(model.ChannelsOffset); err != nil {
+		return 0, &ParseError{Offset: model.ChannelsOffset, Reason: fmt.Sprintf("no channel record where %s channels start (%v); the file layout does not match the model", model.Name, err)}
+	}
+
+	return model.ChannelsOffset, nil
+}
+
+// ForEachChannel decodes channels one at a time and passes each to fn,
+// stopping at the first error from either the parser or fn.
+func (cp *Codeplug) ForEachChannel(fn func(channel *Channel) error) error {
+	totalChannels, err := cp.channelCount()
+	if err != nil {
+		return err
+	}
+
+	currentOffset, err := cp.channelsStartOffset()
+	if err != nil {
+		return err
+	}
 
 	for i := 0; i < totalChannels; i++ {
 		channel, err := cp.readChannelMetadata(currentOffset)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read channel %d: %w", i+1, err)
+			return fmt.Errorf("failed to read channel %d: %w", i+1, err)
 		}
-		channels = append(channels, channel)
+		channel.Index = i
 		currentOffset += int64(channel.TotalLength)
+
+		if err := fn(channel); err != nil {
+			return err
+		}
 	}
 
-	return channels, nil
+	return nil
+}
+
+type SkippedRecord struct {
+	Index  int
+	Offset int64
+	Err    error
+}
+
+// GetChannelsSkippingErrors walks the channel list like GetChannels, but when
+// a record fails to parse it scans forward for the next offset that decodes
+// into a plausible channel and continues from there.
+func (cp *Codeplug) GetChannelsSkippingErrors() ([]*Channel, []SkippedRecord, error) {
+	totalChannels, err := cp.channelCount()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	model, err := cp.Model()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	currentOffset := model.ChannelsOffset
+	channels := make([]*Channel, 0, totalChannels)
+	var skipped []SkippedRecord
+
+	for i := 0; i < totalChannels; i++ {
+		channel, err := cp.readChannelMetadata(currentOffset)
+		if err == nil {
+			channel.Index = i
+			channels = append(channels, channel)
+			currentOffset += int64(channel.TotalLength)
+			continue
+		}
+
+		skipped = append(skipped, SkippedRecord{Index: i, Offset: currentOffset, Err: err})
+
+		next, ok := cp.resyncChannel(currentOffset + 1)
+		if !ok {
+			for j := i + 1; j < totalChannels; j++ {
+				skipped = append(skipped, SkippedRecord{Index: j, Offset: -1, Err: fmt.Errorf("no plausible record found after offset %d", currentOffset)})
+			}
+			break
+		}
+		currentOffset = next
+	}
+
+	return channels, skipped, nil
+}
+
+const maxResyncDistance = 2 * (channelHeaderSize + channelNameSize + channelTrailingSize)
+
+func (cp *Codeplug) resyncChannel(from int64) (int64, bool) {
+	for offset := from; offset < from+maxResyncDistance; offset++ {
+		channel, err := cp.readChannelMetadata(offset)
+		if err == nil && isPlausibleChannel(channel) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+func isPlausibleChannel(channel *Channel) bool {
+	minFreq, maxFreq := mhz(1), mhz(1300)
+	if channel.RxFreq < minFreq || channel.RxFreq > maxFreq {
+		return false
+	}
+	if channel.TxFreq < int32(minFreq) || channel.TxFreq > int32(maxFreq) {
+		return false
+	}
+	if channel.ChannelType > 3 || channel.TxPower > 3 || channel.Bandwidth > 1 {
+		return false
+	}
+	return isPrintableName(channel.Name)
 }
 
 func (cp *Codeplug) GetChannelByIndex(index int) (*Channel, error) {
-	channelCountBuf := make([]byte, 1)
-	if _, err := cp.file.ReadAt(channelCountBuf, totalChannelsAddress); err != nil {
-		return nil, fmt.Errorf("failed to read total channels: %w", err)
+	totalChannels, err := cp.channelCount()
+	if err != nil {
+		return nil, err
 	}
 
-	totalChannels := int(channelCountBuf[0])
 	if index < 0 || index >= totalChannels {
 		return nil, fmt.Errorf("invalid channel index: %d", index)
 	}
 
-	channelsStartOffset := int64(totalChannelsAddress + 1)
-	currentOffset := channelsStartOffset
+	offsets, err := cp.loadChannelOffsets()
+	if err != nil {
+		return nil, err
+	}
 
-	for i := 0; i < index; i++ {
-		channel, err := cp.readChannelMetadata(currentOffset)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read channel %d: %w", i+1, err)
+	channel, err := cp.readChannelMetadata(offsets[index])
+	if err != nil {
+		return nil, err
+	}
+	channel.Index = index
+
+	return channel, nil
+}
+
+func (cp *Codeplug) ReadChannelRecord(channel *Channel) ([]byte, error) {
+	return cp.ReadRaw(channel.Offset, channel.TotalLength)
+}
+
+func (cp *Codeplug) NextFreeChannelIndex() (int, error) {
+	totalChannels, err := cp.channelCount()
+	if err != nil {
+		return 0, err
+	}
+
+	model, err := cp.Model()
+	if err != nil {
+		return 0, err
+	}
+
+	if limit := model.ChannelLimit(); totalChannels >= limit {
+		return 0, fmt.Errorf("codeplug is full: %d of %d channels used", totalChannels, limit)
+	}
+
+	return totalChannels, nil
+}
+
+func (c *Channel) TxDirectionLabel() string {
+	if label, ok := TxDirectionLabels[c.TxFreqDirection]; ok {
+		return label
+	}
+	return fmt.Sprintf("unknown (%d)", c.TxFreqDirection)
+}
+
+// IsDigital reports whether the channel is DMR only. Mixed A+D and D+A
+// channels are not counted as digital.
+func (c *Channel) IsDigital() bool {
+	return c.ChannelType == ChannelTypeDigital
+}
+
+func (c *Channel) ModeLabel() string {
+	if label, ok := ChannelTypeLabels[c.ChannelType]; ok {
+		return label
+	}
+	return fmt.Sprintf("unknown (%d)", c.ChannelType)
+}
+
+func (c *Channel) PowerLabel() string {
+	if label, ok := TxPowerLabels[c.TxPower]; ok {
+		return label
+	}
+	return fmt.Sprintf("unknown (%d)", c.TxPower)
+}
+
+func (c *Channel) BandwidthLabel() string {
+	if label, ok := BandwidthLabels[c.Bandwidth]; ok {
+		return label
+	}
+	return fmt.Sprintf("unknown (%d)", c.Bandwidth)
+}
+
+// EncryptionMode combines the AES key with the multiple and random key
+// flags. A key of 0 means encryption is off.
+func (c *Channel) EncryptionMode() string {
+	if c.AesEncryptionKey == 0 {
+		if c.MultipleKey != 0 || c.RandomKey != 0 {
+			return "invalid (key options set without a key)"
 		}
-		currentOffset += int64(channel.TotalLength)
+		return "off"
+	}
+
+	switch {
+	case c.MultipleKey != 0 && c.RandomKey != 0:
+		return fmt.Sprintf("key %d, multiple keys, random key", c.AesEncryptionKey)
+	case c.MultipleKey != 0:
+		return fmt.Sprintf("key %d, multiple keys", c.AesEncryptionKey)
+	case c.RandomKey != 0:
+		return fmt.Sprintf("key %d, random key", c.AesEncryptionKey)
+	}
+	return fmt.Sprintf("key %d", c.AesEncryptionKey)
+}
+
+func (cp *Codeplug) writeHeader(channel *Channel, fieldOffset int, data []byte) error {
+	offset := channel.Offset + int64(fieldOffset)
+	if _, err := cp.writeFieldAt(data, offset); err != nil {
+		return fmt.Errorf("failed to write channel field at offset %d: %w", offset, err)
+	}
+	return nil
+}
+
+func (cp *Codeplug) writeTrailingByte(channel *Channel, fieldOffset int, value byte) error {
+	if channel.Truncated {
+		return fmt.Errorf("channel %d is truncated at the end of the file; its trailing fields cannot be written", channel.Index)
+	}
+	offset := channel.NameOffset + int64(channel.NameLength) + int64(fieldOffset)
+	if _, err := cp.writeFieldAt([]byte{value}, offset); err != nil {
+		return fmt.Errorf("failed to write channel field at offset %d: %w", offset, err)
+	}
+	return nil
+}
+
+func (cp *Codeplug) SetChannelCorrectFreq(index int, value int) error {
+	if value < math.MinInt8 || value > math.MaxInt8 {
+		return fmt.Errorf("correct frequency %d out of range (%d to %d)", value, math.MinInt8, math.MaxInt8)
+	}
+
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	return cp.writeTrailingByte(channel, trailingCorrectFreqOffset, byte(int8(value)))
+}
+
+func (cp *Codeplug) SetChannelTxDirection(index int, direction byte, offset uint32) error {
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	txFreq := int64(channel.TxFreq)
+	switch direction {
+	case TxDirectionSimplex:
+		txFreq = int64(channel.RxFreq)
+	case TxDirectionPlus:
+		txFreq = int64(channel.RxFreq) + int64(offset)
+	case TxDirectionMinus:
+		txFreq = int64(channel.RxFreq) - int64(offset)
+	case TxDirectionIndependent:
+	default:
+		return fmt.Errorf("invalid tx direction: %d", direction)
+	}
+
+	if txFreq <= 0 || txFreq > math.MaxInt32 {
+		return fmt.Errorf("resulting tx frequency %s MHz is out of range", FormatMHz(txFreq, false))
+	}
+
+	model, err := cp.Model()
+	if err != nil {
+		return err
+	}
+
+	record := make([]byte, headerTxFreqOffset+4-headerTxDirectionOffset)
+	record[0] = direction
+	if err := model.FreqEncoding.Encode(record[headerTxFreqOffset-headerTxDirectionOffset:], uint32(txFreq)); err != nil {
+		return err
+	}
+
+	return cp.writeHeader(channel, headerTxDirectionOffset, record)
+}
+
+// SetChannelType changes a channel's type. DMR only allows 12.5 kHz, so when
+// adjustBandwidth is set a channel switched to digital is also narrowed;
+// the returned bool reports whether that happened.
+func (cp *Codeplug) SetChannelType(index int, channelType byte, adjustBandwidth bool) (bool, error) {
+	if channelType > ChannelTypeDigitalAnalog {
+		return false, fmt.Errorf("invalid channel type: %d", channelType)
+	}
+
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return false, err
+	}
+
+	if err := cp.writeHeader(channel, headerChannelTypeOffset, []byte{channelType}); err != nil {
+		return false, err
+	}
+
+	if !adjustBandwidth || channelType != ChannelTypeDigital || channel.Bandwidth == Bandwidth12_5 {
+		return false, nil
+	}
+	if err := cp.writeHeader(channel, headerBandwidthOffset, []byte{Bandwidth12_5}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetChannelName renames a channel. Names are stored null-terminated
+// inside the record, so a name of a different length resizes the record
+// and moves every later channel and the radio ID list to match. Names must
+// be printable ASCII and fit the model's display width; multibyte UTF-8 is
+// rejected rather than truncated.
+func (cp *Codeplug) SetChannelName(index int, name string) error {
+	if name == "" {
+		return fmt.Errorf("channel name cannot be empty")
+	}
+
+	model, err := cp.Model()
+	if err != nil {
+		return err
+	}
+	if problems := model.ChannelNameProblems(name); len(problems) > 0 {
+		return fmt.Errorf("invalid channel name %q: %s", name, strings.Join(problems, ", "))
+	}
+
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	encoded := append([]byte(name), 0)
+	if len(encoded) == channel.NameLength {
+		if _, err := cp.writeAt(encoded, channel.NameOffset); err != nil {
+			return fmt.Errorf("failed to write channel name at offset %d: %w", channel.NameOffset, err)
+		}
+		return nil
+	}
+
+	if err := cp.replaceBytes(channel.NameOffset, channel.NameLength, encoded); err != nil {
+		return fmt.Errorf("failed to replace channel name: %w", err)
+	}
+	return nil
+}
+
+// SetChannelAprsRx turns APRS receive on or off. APRS is received on the
+// analog side of a channel, so it cannot be enabled on a digital-only one.
+func (cp *Codeplug) SetChannelAprsRx(index int, on bool) error {
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	if on && channel.ChannelType == ChannelTypeDigital {
+		return fmt.Errorf("APRS receive needs an analog channel; channel %d is digital", index)
+	}
+
+	return cp.writeHeader(channel, headerAprsRxOffset, []byte{boolByte(on)})
+}
+
+// SetChannelEncryption writes the AES key and the multiple and random key
+// flags together. The key options only make sense with a key selected, so
+// they are rejected when key is 0.
+func (cp *Codeplug) SetChannelEncryption(index int, key byte, multipleKey, randomKey bool) error {
+	if key == 0 && (multipleKey || randomKey) {
+		return fmt.Errorf("multiple and random key require an encryption key")
+	}
+
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	if err := cp.writeHeader(channel, headerAesKeyOffset, []byte{key}); err != nil {
+		return err
+	}
+	if err := cp.writeTrailingByte(channel, trailingMultipleKeyOffset, boolByte(multipleKey)); err != nil {
+		return err
+	}
+	return cp.writeTrailingByte(channel, trailingRandomKeyOffset, boolByte(randomKey))
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ResetChannel overwrites a channel record with safe defaults: an analog,
+// simplex channel on a placeholder frequency with no tones, named after its
+// position. The record grows or shrinks to fit the name, and everything
+// after it is moved to match. Bytes before the RX frequency are left
+// untouched.
+func (cp *Codeplug) ResetChannel(index int) error {
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	model, err := cp.Model()
+	if err != nil {
+		return err
+	}
+
+	record, err := placeholderChannelRecord(model, index)
+	if err != nil {
+		return err
+	}
+	if err := cp.readAt(record[:headerRxFreqOffset], channel.Offset); err != nil {
+		return fmt.Errorf("failed to read channel header at offset %d: %w", channel.Offset, err)
+	}
+
+	if len(record) != channel.TotalLength {
+		return cp.ReplaceChannelRecord(index, record, model)
+	}
+	if _, err := cp.writeAt(record, channel.Offset); err != nil {
+		return fmt.Errorf("failed to write channel at offset %d: %w", channel.Offset, err)
+	}
+	return nil
+}
+
+// placeholderChannelRecord builds the record of a freshly reset channel: an
+// analog 146.52 MHz simplex channel named after its position, with every
+// other byte zero.
+func placeholderChannelRecord(model Model, index int) ([]byte, error) {
+	placeholderFreq := mhz(146.52)
+	name := fmt.Sprintf("Channel %d", index+1)
+
+	record := make([]byte, channelHeaderSize+len(name)+1+channelTrailingSize)
+	if err := model.FreqEncoding.Encode(record[headerRxFreqOffset:], placeholderFreq); err != nil {
+		return nil, err
+	}
+	record[headerTxDirectionOffset] = TxDirectionSimplex
+	if err := model.FreqEncoding.Encode(record[headerTxFreqOffset:], placeholderFreq); err != nil {
+		return nil, err
+	}
+	record[headerBandwidthOffset] = Bandwidth25
+	record[headerScanListOffset] = 0xFF
+	copy(record[channelHeaderSize:], name)
+	return record, nil
+}
+
+// placeholderChannel is the decoded form of placeholderChannelRecord.
+func placeholderChannel(index int) Channel {
+	return Channel{
+		Index:           index,
+		Name:            fmt.Sprintf("Channel %d", index+1),
+		RxFreq:          mhz(146.52),
+		TxFreqDirection: TxDirectionSimplex,
+		TxFreq:          int32(mhz(146.52)),
+		ChannelType:     ChannelTypeAnalog,
+		Bandwidth:       Bandwidth25,
+		ScanList:        -1,
+	}
+}
+
+// SwapChannelFrequencies exchanges a channel's RX and TX frequencies and
+// flips a +/- offset direction to match, e.g. to listen on a repeater input.
+func (cp *Codeplug) SwapChannelFrequencies(index int) error {
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	if channel.TxFreq <= 0 {
+		return fmt.Errorf("channel %d has an invalid tx frequency", index)
+	}
+	newRxFreq := uint32(channel.TxFreq)
+	newTxFreq := channel.RxFreq
+
+	model, err := cp.Model()
+	if err != nil {
+		return err
+	}
+	for _, freq := range []uint32{newRxFreq, newTxFreq} {
+		if err := model.CheckFrequency(freq); err != nil {
+			return err
+		}
+	}
+
+	direction := channel.TxFreqDirection
+	switch direction {
+	case TxDirectionPlus:
+		direction = TxDirectionMinus
+	case TxDirectionMinus:
+		direction = TxDirectionPlus
+	}
+
+	record := make([]byte, headerTxFreqOffset+4-headerRxFreqOffset)
+	if err := model.FreqEncoding.Encode(record, newRxFreq); err != nil {
+		return err
+	}
+	record[headerTxDirectionOffset-headerRxFreqOffset] = direction
+	if err := model.FreqEncoding.Encode(record[headerTxFreqOffset-headerRxFreqOffset:], newTxFreq); err != nil {
+		return err
+	}
+
+	return cp.writeHeader(channel, headerRxFreqOffset, record)
+}
+
+// ClearChannels removes every channel record, moving the sections that
+// follow the channel list up, and returns the number of channels removed.
+func (cp *Codeplug) ClearChannels() (int, error) {
+	count, err := cp.channelCount()
+	if err != nil {
+		return 0, err
+	}
+
+	channelsEndOffset, err := cp.channelsEndOffset()
+	if err != nil {
+		return 0, err
+	}
+
+	channelsStartOffset, err := cp.channelsStartOffset()
+	if err != nil {
+		return 0, err
+	}
+	if err := cp.removeBytes(channelsStartOffset, int(channelsEndOffset-channelsStartOffset)); err != nil {
+		return 0, fmt.Errorf("failed to remove channel records: %w", err)
+	}
+
+	if err := cp.setChannelCount(0); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteChannel removes a channel record and decrements the channel count.
+// Everything after the record, including the radio ID list, moves up by the
+// record's length, and later channels are renumbered. Zones and scan lists
+// that refer to channels by index are not updated.
+func (cp *Codeplug) DeleteChannel(index int) error {
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+	count, err := cp.channelCount()
+	if err != nil {
+		return err
+	}
+
+	if err := cp.removeBytes(channel.Offset, channel.TotalLength); err != nil {
+		return fmt.Errorf("failed to remove channel record: %w", err)
+	}
+	return cp.setChannelCount(count - 1)
+}
+
+// ReplaceChannelRecord swaps the record at index for another one, which may
+// have a different length. The channel count is unchanged. The new record
+// is checked first and the file after it is rewritten once, so a rejected
+// or failed replacement never loses the channel.
+func (cp *Codeplug) ReplaceChannelRecord(index int, record []byte, srcModel Model) error {
+	record, err := cp.prepareChannelRecord(record, srcModel)
+	if err != nil {
+		return err
+	}
+
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	if err := cp.replaceBytes(channel.Offset, channel.TotalLength, record); err != nil {
+		return fmt.Errorf("failed to replace channel record: %w", err)
+	}
+	return nil
+}
+
+// ChannelRecordWithName returns a copy of a channel record read with
+// ReadChannelRecord carrying a different name. The record grows or shrinks
+// with the name.
+func ChannelRecordWithName(record []byte, name string) ([]byte, error) {
+	if len(record) < channelHeaderSize+1+channelTrailingSize {
+		return nil, fmt.Errorf("channel record is too short: %d bytes", len(record))
+	}
+	if strings.IndexByte(name, 0) >= 0 {
+		return nil, fmt.Errorf("channel name cannot contain a NUL byte")
+	}
+
+	trailing := record[len(record)-channelTrailingSize:]
+	renamed := make([]byte, 0, channelHeaderSize+len(name)+1+channelTrailingSize)
+	renamed = append(renamed, record[:channelHeaderSize]...)
+	renamed = append(renamed, name...)
+	renamed = append(renamed, 0)
+	return append(renamed, trailing...), nil
+}
+
+// InsertChannelRecord inserts a raw channel record read from a codeplug for
+// srcModel before the channel at index, or appends it when index equals the
+// channel count. Frequencies are re-encoded when the two models store them
+// differently; every other byte is copied unchanged.
+func (cp *Codeplug) InsertChannelRecord(index int, record []byte, srcModel Model) error {
+	record, err := cp.prepareChannelRecord(record, srcModel)
+	if err != nil {
+		return err
+	}
+	return cp.insertChannelRecord(index, record)
+}
+
+// prepareChannelRecord checks the length and name of a record read from a
+// codeplug for srcModel and returns a copy with its frequencies re-encoded
+// for this codeplug's model.
+func (cp *Codeplug) prepareChannelRecord(record []byte, srcModel Model) ([]byte, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	if minLength, maxLength := model.ChannelRecordSize(); len(record) < minLength || len(record) > maxLength {
+		return nil, fmt.Errorf("channel record is %d bytes long, %s records are %d to %d bytes", len(record), model.Name, minLength, maxLength)
+	}
+	nameLength := len(record) - channelHeaderSize - channelTrailingSize - 1
+	if record[channelHeaderSize+nameLength] != 0 {
+		return nil, fmt.Errorf("channel record name is not terminated")
+	}
+	if err := model.CheckChannelName(string(record[channelHeaderSize : channelHeaderSize+nameLength])); err != nil {
+		return nil, err
+	}
+
+	record = append([]byte(nil), record...)
+	if srcModel.FreqEncoding != model.FreqEncoding {
+		for _, fieldOffset := range []int{headerRxFreqOffset, headerTxFreqOffset} {
+			freq, err := srcModel.FreqEncoding.Decode(record[fieldOffset:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode frequency: %w", err)
+			}
+			if err := model.FreqEncoding.Encode(record[fieldOffset:], freq); err != nil {
+				return nil, fmt.Errorf("failed to encode frequency: %w", err)
+			}
+		}
+	}
+	return record, nil
+}
+
+// insertChannelRecord inserts a record already checked by
+// prepareChannelRecord.
+func (cp *Codeplug) insertChannelRecord(index int, record []byte) error {
+	count, err := cp.NextFreeChannelIndex()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index > count {
+		return fmt.Errorf("invalid channel index: %d", index)
+	}
+
+	var offset int64
+	if index == count {
+		offset, err = cp.channelsEndOffset()
+	} else {
+		var channel *Channel
+		channel, err = cp.GetChannelByIndex(index)
+		if channel != nil {
+			offset = channel.Offset
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cp.insertBytes(offset, record); err != nil {
+		return fmt.Errorf("failed to insert channel record: %w", err)
 	}
 
-	return cp.readChannelMetadata(currentOffset)
+	return cp.setChannelCount(count + 1)
 }
@@ -0,0 +1,241 @@
+package codeplug
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func FuzzReadChannelMetadata(f *testing.F) {
+	f.Add(channelRecord(testChannels[0]))
+	f.Add(channelRecord(testChannel{name: "0123456789ABCDEF", rx: mhz(446), tx: mhz(446)}))
+	f.Add(channelRecord(testChannel{name: "", rx: mhz(146.52), tx: mhz(146.52)}))
+
+	header := buildCodeplug(nil, nil)[:d878ChannelsOffset]
+	f.Fuzz(func(t *testing.T, record []byte) {
+		cp := NewFromBytes(append(append([]byte(nil), header...), record...))
+
+		channel, err := cp.readChannelMetadata(d878ChannelsOffset)
+		if err != nil {
+			return
+		}
+
+		minLength, _ := defaultModel.ChannelRecordSize()
+		maxLength := channelHeaderSize + channelNameSize + channelTrailingSize
+		if channel.TotalLength < minLength || channel.TotalLength > maxLength {
+			t.Fatalf("accepted a %d byte record, outside %d to %d", channel.TotalLength, minLength, maxLength)
+		}
+		if channel.NameLength != len(channel.Name)+1 || bytes.IndexByte([]byte(channel.Name), 0) >= 0 {
+			t.Fatalf("name %q does not match its length %d", channel.Name, channel.NameLength)
+		}
+		if channel.NameOffset+int64(channel.NameLength) > int64(d878ChannelsOffset+len(record)) {
+			t.Fatalf("name ends past the end of the data")
+		}
+	})
+}
+
+func TestDeleteChannelKeepsRadioIDs(t *testing.T) {
+	for index := range testChannels {
+		cp := newTestCodeplug(t)
+		if err := cp.DeleteChannel(index); err != nil {
+			t.Fatalf("DeleteChannel(%d): %v", index, err)
+		}
+
+		want := append(append([]testChannel(nil), testChannels[:index]...), testChannels[index+1:]...)
+		checkCodeplug(t, cp, want, testRadioIDs)
+
+		info, err := cp.GetInfo()
+		if err != nil {
+			t.Fatalf("GetInfo after deleting channel %d: %v", index, err)
+		}
+		if info.ChannelCount != len(want) || len(info.RadioIDs) != len(testRadioIDs) {
+			t.Errorf("GetInfo after deleting channel %d = %d channels, %d radio IDs", index, info.ChannelCount, len(info.RadioIDs))
+		}
+	}
+}
+
+func TestChannelNameLengthLimit(t *testing.T) {
+	cp := newTestCodeplug(t)
+	model, err := cp.Model()
+	if err != nil {
+		t.Fatalf("Model: %v", err)
+	}
+
+	atLimit := strings.Repeat("N", model.MaxChannelNameLength)
+	if err := cp.SetChannelName(1, atLimit); err != nil {
+		t.Fatalf("SetChannelName with %d characters: %v", len(atLimit), err)
+	}
+	channel, err := cp.GetChannelByIndex(1)
+	if err != nil {
+		t.Fatalf("GetChannelByIndex: %v", err)
+	}
+	if _, maxLength := model.ChannelRecordSize(); channel.Name != atLimit || channel.TotalLength != maxLength {
+		t.Errorf("channel 1 = %q, %d bytes, want %q, %d bytes", channel.Name, channel.TotalLength, atLimit, maxLength)
+	}
+
+	overLimit := atLimit + "X"
+	if err := cp.SetChannelName(1, overLimit); err == nil {
+		t.Errorf("SetChannelName accepted %d characters, limit is %d", len(overLimit), model.MaxChannelNameLength)
+	}
+	record := channelRecord(testChannel{name: overLimit, rx: mhz(146.52), tx: mhz(146.52)})
+	if err := cp.InsertChannelRecord(0, record, model); err == nil {
+		t.Errorf("InsertChannelRecord accepted a %d byte record", len(record))
+	}
+
+	want := append([]testChannel(nil), testChannels...)
+	want[1].name = atLimit
+	checkCodeplug(t, cp, want, testRadioIDs)
+}
+
+func TestRadioIDNameLengthLimit(t *testing.T) {
+	model, _ := LookupModel("D878UV2")
+	if err := model.CheckRadioIDName(strings.Repeat("R", model.MaxRadioIDNameLength)); err != nil {
+		t.Errorf("CheckRadioIDName at the limit: %v", err)
+	}
+	if err := model.CheckRadioIDName(strings.Repeat("R", model.MaxRadioIDNameLength+1)); err == nil {
+		t.Error("CheckRadioIDName accepted a name one over the limit")
+	}
+}
+
+func TestTruncatedFinalChannel(t *testing.T) {
+	data := buildCodeplug(testChannels, nil)
+	end := len(data) - 256 - radioIDSectionGap
+	last := len(channelRecord(testChannels[len(testChannels)-1]))
+
+	// Cut inside the trailing bytes: the channel is still listed, marked
+	// truncated, and its trailing fields cannot be written.
+	cp := NewFromBytes(data[:end-10])
+	channels, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels with short trailing bytes: %v", err)
+	}
+	final := channels[len(channels)-1]
+	if !final.Truncated || final.Name != testChannels[len(testChannels)-1].name {
+		t.Errorf("final channel = %q, truncated %v", final.Name, final.Truncated)
+	}
+	if err := cp.SetChannelCorrectFreq(final.Index, 1); err == nil {
+		t.Error("SetChannelCorrectFreq wrote to a truncated channel")
+	}
+
+	// Cut inside the name or header: reading fails with an error.
+	for _, cut := range []int{last - channelTrailingSize - 1, last - channelTrailingSize - 3, channelHeaderSize / 2, 1} {
+		cp := NewFromBytes(data[:end-last+cut])
+		if channels, err := cp.GetChannels(); err == nil {
+			t.Errorf("record cut after %d bytes: got %d channels, want an error", cut, len(channels))
+		}
+		if _, err := cp.GetChannelByIndex(len(testChannels) - 1); err == nil {
+			t.Errorf("record cut after %d bytes: GetChannelByIndex succeeded", cut)
+		}
+	}
+}
+
+func TestReplaceChannelRecordRejectsBeforeRemoving(t *testing.T) {
+	cp := newTestCodeplug(t)
+	model, err := cp.Model()
+	if err != nil {
+		t.Fatalf("Model: %v", err)
+	}
+
+	unterminated := channelRecord(testChannel{name: "Bad", rx: mhz(146.52), tx: mhz(146.52)})
+	unterminated[channelHeaderSize+len("Bad")] = 'X'
+	for _, record := range [][]byte{
+		channelRecord(testChannel{name: strings.Repeat("N", model.MaxChannelNameLength+1), rx: mhz(146.52), tx: mhz(146.52)}),
+		unterminated,
+		{1, 2, 3},
+	} {
+		if err := cp.ReplaceChannelRecord(1, record, model); err == nil {
+			t.Errorf("ReplaceChannelRecord accepted a %d byte record", len(record))
+		}
+		checkCodeplug(t, cp, testChannels, testRadioIDs)
+	}
+
+	writes := 0
+	cp.SetMutationHook(func(offset int64, old, new []byte) {
+		if len(new) > 0 {
+			writes++
+		}
+	})
+	replacement := testChannel{name: "Replaced Channel", rx: mhz(445.5), tx: mhz(440.5)}
+	if err := cp.ReplaceChannelRecord(1, channelRecord(replacement), model); err != nil {
+		t.Fatalf("ReplaceChannelRecord: %v", err)
+	}
+	if writes != 1 {
+		t.Errorf("ReplaceChannelRecord made %d writes, want one", writes)
+	}
+	want := append([]testChannel(nil), testChannels...)
+	want[1] = replacement
+	checkCodeplug(t, cp, want, testRadioIDs)
+}
+
+func TestResetChannelFitsName(t *testing.T) {
+	channels := append([]testChannel(nil), testChannels...)
+	channels[0].name = "A"
+	channels[2].name = "A much longer nm"
+
+	for _, index := range []int{0, 2} {
+		cp := NewFromBytes(buildCodeplug(channels, testRadioIDs))
+		if err := cp.ResetChannel(index); err != nil {
+			t.Fatalf("ResetChannel(%d): %v", index, err)
+		}
+
+		want := append([]testChannel(nil), channels...)
+		want[index] = testChannel{name: fmt.Sprintf("Channel %d", index+1), rx: mhz(146.52), tx: mhz(146.52)}
+		checkCodeplug(t, cp, want, testRadioIDs)
+
+		channel, err := cp.GetChannelByIndex(index)
+		if err != nil {
+			t.Fatalf("GetChannelByIndex: %v", err)
+		}
+		if channel.ChannelType != ChannelTypeAnalog || channel.DecodeRxTone() != "Off" || channel.ScanList != -1 {
+			t.Errorf("channel %d after reset = type %d, tone %s, scan list %d", index, channel.ChannelType, channel.DecodeRxTone(), channel.ScanList)
+		}
+	}
+}
+
+func TestInsertChannelRecordReencodesFrequencies(t *testing.T) {
+	cp := newTestCodeplug(t)
+	model, err := cp.Model()
+	if err != nil {
+		t.Fatalf("Model: %v", err)
+	}
+
+	bcdModel := model
+	bcdModel.FreqEncoding = FreqEncodingBCD
+	record := channelRecord(testChannel{name: "BCD"})
+	copy(record[headerRxFreqOffset:], []byte{0x14, 0x65, 0x20, 0x00})
+	copy(record[headerTxFreqOffset:], []byte{0x14, 0x60, 0x20, 0x00})
+
+	if err := cp.InsertChannelRecord(len(testChannels), record, bcdModel); err != nil {
+		t.Fatalf("InsertChannelRecord: %v", err)
+	}
+	want := append(append([]testChannel(nil), testChannels...), testChannel{name: "BCD", rx: mhz(146.52), tx: mhz(146.02)})
+	checkCodeplug(t, cp, want, testRadioIDs)
+}
+
+func TestSetChannelNameMovesTailOnce(t *testing.T) {
+	for _, name := range []string{"A longer name", "S"} {
+		cp := newTestCodeplug(t)
+		channels, err := cp.GetChannels()
+		if err != nil {
+			t.Fatalf("GetChannels: %v", err)
+		}
+
+		var writes []int64
+		cp.SetMutationHook(func(offset int64, old, new []byte) {
+			if len(new) > 0 {
+				writes = append(writes, offset)
+			}
+		})
+		if err := cp.SetChannelName(1, name); err != nil {
+			t.Fatalf("SetChannelName(%q): %v", name, err)
+		}
+		if len(writes) != 1 || writes[0] != channels[1].NameOffset {
+			t.Errorf("rename to %q wrote at offsets %v, want one write at %d", name, writes, channels[1].NameOffset)
+		}
+
+		want := append([]testChannel(nil), testChannels...)
+		want[1].name = name
+		checkCodeplug(t, cp, want, testRadioIDs)
+	}
+}
@@ -0,0 +1,49 @@
+package codeplug
+
+import "fmt"
+
+// loadChannelOffsets returns the offset of every channel record followed by
+// the end of the channel list. The records are walked once and the result
+// is reused until a write could have moved them, so repeated lookups and
+// radio ID updates do not re-read the whole channel list.
+func (cp *Codeplug) loadChannelOffsets() ([]int64, error) {
+	if cp.channelOffsets != nil {
+		return cp.channelOffsets, nil
+	}
+
+	totalChannels, err := cp.channelCount()
+	if err != nil {
+		return nil, err
+	}
+
+	currentOffset, err := cp.channelsStartOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([]int64, 0, totalChannels+1)
+	for i := 0; i < totalChannels; i++ {
+		channel, err := cp.readChannelMetadata(currentOffset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read channel %d: %w", i+1, err)
+		}
+		offsets = append(offsets, currentOffset)
+		currentOffset += int64(channel.TotalLength)
+	}
+
+	cp.channelOffsets = append(offsets, currentOffset)
+	return cp.channelOffsets, nil
+}
+
+// invalidateChannelOffsets drops the cached offsets if a change at offset
+// could affect them: anything before the end of the channel list, which
+// includes the channel count. Changes to the radio ID list and later
+// sections leave the channel records where they are.
+func (cp *Codeplug) invalidateChannelOffsets(offset int64) {
+	if cp.channelOffsets == nil {
+		return
+	}
+	if offset < cp.channelOffsets[len(cp.channelOffsets)-1] {
+		cp.channelOffsets = nil
+	}
+}
@@ -0,0 +1,51 @@
+package codeplug
+
+import (
+	"fmt"
+)
+
+// ChannelRef identifies a channel record without decoding it. Refs are
+// produced by a walk that only reads channel names, which is considerably
+// cheaper than GetChannels for list-then-inspect workflows.
+type ChannelRef struct {
+	Index  int
+	Name   string
+	Offset int64
+
+	cp *Codeplug
+}
+
+func (cp *Codeplug) ChannelRefs() ([]ChannelRef, error) {
+	totalChannels, err := cp.channelCount()
+	if err != nil {
+		return nil, err
+	}
+
+	currentOffset, err := cp.channelsStartOffset()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]ChannelRef, 0, totalChannels)
+
+	for i := 0; i < totalChannels; i++ {
+		name, nameLength, err := cp.readChannelName(currentOffset + channelHeaderSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read channel %d: %w", i+1, err)
+		}
+
+		refs = append(refs, ChannelRef{Index: i, Name: name, Offset: currentOffset, cp: cp})
+		currentOffset += int64(channelHeaderSize + nameLength + channelTrailingSize)
+	}
+
+	return refs, nil
+}
+
+func (r *ChannelRef) Resolve() (*Channel, error) {
+	channel, err := r.cp.readChannelMetadata(r.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read channel %d: %w", r.Index+1, err)
+	}
+	channel.Index = r.Index
+
+	return channel, nil
+}
@@ -0,0 +1,286 @@
+package codeplug
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// chirpColumns are the CHIRP CSV columns PlanChirpCSV reads. CHIRP writes
+// more, such as TStep, Skip and the D-STAR call signs, which are ignored.
+var chirpColumns = []string{
+	"Location", "Name", "Frequency", "Duplex", "Offset", "Tone",
+	"rToneFreq", "cToneFreq", "DtcsCode", "DtcsPolarity", "Mode",
+}
+
+// chirpRow is one CHIRP row, looked up by column name.
+type chirpRow map[string]string
+
+// PlanChirpCSV reads a CSV exported by CHIRP and plans the analog channels
+// it describes, without writing anything. A row whose Location is an
+// existing channel index updates that channel; every other row adds a new
+// channel after the last one, in file order. Rows that need something this
+// radio cannot store from a CHIRP row, such as a digital or AM mode, a
+// disabled transmitter or reverse tone squelch, are listed in Skipped.
+func PlanChirpCSV(cp *Codeplug, r io.Reader) (*ChannelImport, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+	count, err := cp.NextFreeChannelIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	for _, name := range chirpColumns {
+		if !containsString(header, name) {
+			return nil, fmt.Errorf("CSV has no %s column: is it a CHIRP export?", name)
+		}
+	}
+
+	plan := &ChannelImport{}
+	seen := make(map[int]int)
+	next := count
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var line int
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line, err = parseErr.StartLine, parseErr.Err
+			}
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: -1, Err: err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := make(chirpRow, len(header))
+		for i, name := range header {
+			if i < len(fields) {
+				row[name] = strings.TrimSpace(fields[i])
+			}
+		}
+
+		location, err := strconv.Atoi(row["Location"])
+		if err != nil || location < 0 {
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: -1, Err: fmt.Errorf("invalid location %q", row["Location"])})
+			continue
+		}
+
+		update := ChannelUpdate{Line: line}
+		if location < count {
+			if first, ok := seen[location]; ok {
+				plan.Errors = append(plan.Errors, RowError{Line: line, Index: location, Err: fmt.Errorf("channel already updated on line %d", first)})
+				continue
+			}
+			seen[location] = line
+			update.Before, err = cp.GetChannelByIndex(location)
+			if err != nil {
+				plan.Errors = append(plan.Errors, RowError{Line: line, Index: location, Err: err})
+				continue
+			}
+		} else {
+			placeholder := placeholderChannel(next)
+			update.New, update.Before = true, &placeholder
+		}
+
+		update.After = *update.Before
+		skip, err := applyChirpRow(&update.After, row, model)
+		if err != nil {
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: update.Before.Index, Err: err})
+			continue
+		}
+		if skip != "" {
+			plan.Skipped = append(plan.Skipped, RowError{Line: line, Index: -1, Err: errors.New(skip)})
+			continue
+		}
+
+		if update.New {
+			if next >= model.ChannelLimit() {
+				plan.Errors = append(plan.Errors, RowError{Line: line, Index: next, Err: fmt.Errorf("%s holds at most %d channels", model.Name, model.ChannelLimit())})
+				continue
+			}
+			next++
+		}
+		plan.Updates = append(plan.Updates, update)
+	}
+
+	return plan, nil
+}
+
+// applyChirpRow sets the fields of c that a CHIRP row describes. It returns
+// a reason instead when the row has to be skipped, and an error when a
+// value is invalid.
+func applyChirpRow(c *Channel, row chirpRow, model Model) (string, error) {
+	switch row["Mode"] {
+	case "FM":
+		c.Bandwidth = Bandwidth25
+	case "NFM":
+		c.Bandwidth = Bandwidth12_5
+	default:
+		return fmt.Sprintf("mode %q is not analog FM", row["Mode"]), nil
+	}
+
+	rxFreq, err := parseCSVFreq(row["Frequency"], model)
+	if err != nil {
+		return "", err
+	}
+	txFreq, direction, skip, err := chirpTxFreq(rxFreq, row["Duplex"], row["Offset"])
+	if skip != "" || err != nil {
+		return skip, err
+	}
+	if err := model.CheckFrequency(txFreq); err != nil {
+		return "", fmt.Errorf("tx frequency: %w", err)
+	}
+
+	txOption, txTone, rxOption, rxTone, skip, err := chirpTones(row)
+	if skip != "" || err != nil {
+		return skip, err
+	}
+
+	if name := row["Name"]; name != "" {
+		if err := model.CheckChannelName(name); err != nil {
+			return "", err
+		}
+		c.Name = name
+	}
+	// CHIRP power levels are named per radio; only the ones this radio also
+	// has are used, and anything else keeps the channel's power.
+	for value, label := range TxPowerLabels {
+		if strings.EqualFold(row["Power"], label) {
+			c.TxPower = value
+		}
+	}
+
+	c.ChannelType = ChannelTypeAnalog
+	c.RxFreq, c.TxFreq, c.TxFreqDirection = rxFreq, int32(txFreq), direction
+	c.CtcssDcsEncodeOption, c.CtcssDcsEncode = txOption, txTone
+	c.CtcssDcsDecodeOption, c.CtcssDcsDecode = rxOption, rxTone
+	return "", nil
+}
+
+// chirpTxFreq turns CHIRP's Duplex and Offset into a TX frequency and
+// direction. With split duplex the offset is the TX frequency itself.
+func chirpTxFreq(rxFreq uint32, duplex, offset string) (uint32, byte, string, error) {
+	if duplex == "" {
+		return rxFreq, TxDirectionSimplex, "", nil
+	}
+	if duplex == "off" {
+		return 0, 0, "transmit is disabled", nil
+	}
+
+	mhz, err := strconv.ParseFloat(offset, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid offset %q", offset)
+	}
+	offsetFreq, err := MHzToFreq(mhz)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	txFreq := int64(rxFreq)
+	direction := TxDirectionIndependent
+	switch duplex {
+	case "+":
+		txFreq, direction = txFreq+int64(offsetFreq), TxDirectionPlus
+	case "-":
+		txFreq, direction = txFreq-int64(offsetFreq), TxDirectionMinus
+	case "split":
+		txFreq = int64(offsetFreq)
+	default:
+		return 0, 0, "", fmt.Errorf("invalid duplex %q: use blank, +, -, split or off", duplex)
+	}
+	if txFreq <= 0 || txFreq > math.MaxInt32 {
+		return 0, 0, "", fmt.Errorf("tx frequency %s MHz is out of range", FormatMHz(txFreq, false))
+	}
+	return uint32(txFreq), direction, "", nil
+}
+
+// chirpTones turns CHIRP's tone mode and tone columns into the TX and RX
+// tone options and values. Tone sends rToneFreq only, TSQL uses cToneFreq
+// both ways, DTCS uses DtcsCode both ways and Cross follows CrossMode, TX
+// side first. DtcsPolarity is two letters, TX then RX, N or R.
+func chirpTones(row chirpRow) (txOption, txTone, rxOption, rxTone byte, skip string, err error) {
+	ctcss := func(column string) (byte, byte, error) {
+		return ParseTone(row[column])
+	}
+	dcs := func(column string, side int) (byte, byte, error) {
+		polarity := "N"
+		if p := row["DtcsPolarity"]; len(p) == 2 && p[side] == 'R' {
+			polarity = "I"
+		}
+		return ParseTone("D" + row[column] + polarity)
+	}
+
+	var txErr, rxErr error
+	switch mode := row["Tone"]; mode {
+	case "":
+	case "Tone":
+		txOption, txTone, txErr = ctcss("rToneFreq")
+	case "TSQL":
+		txOption, txTone, txErr = ctcss("cToneFreq")
+		rxOption, rxTone, rxErr = ctcss("cToneFreq")
+	case "DTCS":
+		txOption, txTone, txErr = dcs("DtcsCode", 0)
+		rxOption, rxTone, rxErr = dcs("DtcsCode", 1)
+	case "Cross":
+		tx, rx, ok := strings.Cut(row["CrossMode"], "->")
+		if !ok {
+			return 0, 0, 0, 0, "", fmt.Errorf("invalid cross mode %q", row["CrossMode"])
+		}
+		switch tx {
+		case "":
+		case "Tone":
+			txOption, txTone, txErr = ctcss("rToneFreq")
+		case "DTCS":
+			txOption, txTone, txErr = dcs("DtcsCode", 0)
+		default:
+			return 0, 0, 0, 0, "", fmt.Errorf("invalid cross mode %q", row["CrossMode"])
+		}
+		switch rx {
+		case "":
+		case "Tone":
+			rxOption, rxTone, rxErr = ctcss("cToneFreq")
+		case "DTCS":
+			rxOption, rxTone, rxErr = dcs("RxDtcsCode", 1)
+		default:
+			return 0, 0, 0, 0, "", fmt.Errorf("invalid cross mode %q", row["CrossMode"])
+		}
+	case "TSQL-R", "DTCS-R":
+		return 0, 0, 0, 0, fmt.Sprintf("tone mode %s (reverse squelch) is not supported", mode), nil
+	default:
+		return 0, 0, 0, 0, "", fmt.Errorf("invalid tone mode %q", mode)
+	}
+
+	if txErr != nil {
+		return 0, 0, 0, 0, "", fmt.Errorf("tx tone: %w", txErr)
+	}
+	if rxErr != nil {
+		return 0, 0, 0, 0, "", fmt.Errorf("rx tone: %w", rxErr)
+	}
+	return txOption, txTone, rxOption, rxTone, "", nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
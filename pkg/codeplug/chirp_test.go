@@ -0,0 +1,88 @@
+package codeplug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanChirpCSV(t *testing.T) {
+	cp := newTestCodeplug(t)
+	csv := `Location,Name,Frequency,Duplex,Offset,Tone,rToneFreq,cToneFreq,DtcsCode,DtcsPolarity,RxDtcsCode,CrossMode,Mode,TStep,Skip,Power,Comment
+1,W1AW,146.940000,-,0.600000,Tone,100.0,88.5,023,NN,023,Tone->Tone,FM,5.00,,Low,
+4,Split,147.000000,split,147.600000,DTCS,88.5,88.5,754,NR,023,Tone->Tone,NFM,5.00,,High,
+5,Cross,446.000000,,0.000000,Cross,88.5,100.0,023,NN,754,DTCS->Tone,FM,5.00,,5.0W,
+6,AM Air,127.000000,,0.000000,,88.5,88.5,023,NN,023,Tone->Tone,AM,5.00,,High,
+7,DMR,445.000000,,0.000000,,88.5,88.5,023,NN,023,Tone->Tone,DMR,5.00,,High,
+8,RX Only,146.520000,off,0.000000,,88.5,88.5,023,NN,023,Tone->Tone,FM,5.00,,High,
+`
+	plan, err := PlanChirpCSV(cp, strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("PlanChirpCSV: %v", err)
+	}
+	if len(plan.Errors) != 0 {
+		t.Fatalf("errors = %v", plan.Errors)
+	}
+	if len(plan.Skipped) != 3 || plan.Skipped[0].Line != 5 || plan.Skipped[2].Line != 7 {
+		t.Fatalf("skipped = %v, want lines 5 to 7", plan.Skipped)
+	}
+	if len(plan.Updates) != 3 || plan.Updates[0].New || !plan.Updates[1].New || plan.Updates[2].Before.Index != 5 {
+		t.Fatalf("updates = %+v, want channel 1 updated and channels 4 and 5 added", plan.Updates)
+	}
+
+	result, err := plan.Apply(cp)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if result.Added != 2 || result.Updated != 1 {
+		t.Errorf("result = %+v, want 2 added and 1 updated", result)
+	}
+
+	want := append([]testChannel(nil), testChannels...)
+	want[1].name = "W1AW"
+	want = append(want,
+		testChannel{name: "Split", rx: mhz(147), tx: mhz(147.6)},
+		testChannel{name: "Cross", rx: mhz(446), tx: mhz(446)},
+	)
+	checkCodeplug(t, cp, want, testRadioIDs)
+
+	channels, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels: %v", err)
+	}
+	for _, check := range []struct {
+		index            int
+		direction, power byte
+		bandwidth        byte
+		rxTone, txTone   string
+	}{
+		{1, TxDirectionMinus, TxPowerLow, Bandwidth25, "Off", "100.0 Hz"},
+		{4, TxDirectionIndependent, TxPowerHigh, Bandwidth12_5, "D754I", "D754N"},
+		{5, TxDirectionSimplex, TxPowerLow, Bandwidth25, "100.0 Hz", "D023N"},
+	} {
+		c := channels[check.index]
+		if c.TxFreqDirection != check.direction || c.TxPower != check.power || c.Bandwidth != check.bandwidth ||
+			c.DecodeRxTone() != check.rxTone || c.DecodeTxTone() != check.txTone || c.ChannelType != ChannelTypeAnalog {
+			t.Errorf("channel %d = direction %d power %d bandwidth %d tones %s/%s, want %d %d %d %s/%s",
+				check.index, c.TxFreqDirection, c.TxPower, c.Bandwidth, c.DecodeRxTone(), c.DecodeTxTone(),
+				check.direction, check.power, check.bandwidth, check.rxTone, check.txTone)
+		}
+	}
+}
+
+func TestPlanChirpCSVRejectsInvalidRows(t *testing.T) {
+	cp := newTestCodeplug(t)
+	csv := `Location,Name,Frequency,Duplex,Offset,Tone,rToneFreq,cToneFreq,DtcsCode,DtcsPolarity,Mode
+0,Bad Duplex,146.52,x,0.6,,88.5,88.5,023,NN,FM
+1,Bad Tone,146.52,,0,Tone,12.3,88.5,023,NN,FM
+`
+	plan, err := PlanChirpCSV(cp, strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("PlanChirpCSV: %v", err)
+	}
+	if len(plan.Errors) != 2 || len(plan.Updates) != 0 {
+		t.Fatalf("plan = %+v, want two errors", plan)
+	}
+	if _, err := plan.Apply(cp); err == nil {
+		t.Error("Apply wrote a plan with invalid rows")
+	}
+}
@@ -1,8 +1,15 @@
 package codeplug
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 const (
@@ -11,33 +18,190 @@ const (
 	modelOffset          = 0x09
 	modelSize            = 10
 	maxRadioIDs          = 10
+	maxBackupAttempts    = 100
 )
 
+var ErrFileLocked = errors.New("codeplug file is in use by another process")
+
+var ErrFileTooSmall = errors.New("file is too small to be a codeplug")
+
+// ParseError reports data in the file that could not be decoded, together
+// with the offset where decoding failed.
+type ParseError struct {
+	Offset int64
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Reason, e.Offset)
+}
+
 type Codeplug struct {
-	file *os.File
-	path string
+	rw           ReaderWriterAt
+	file         *os.File
+	path         string
+	verifyWrites bool
+	logger       *log.Logger
+	onMutation   MutationHook
+
+	// channelOffsets caches where each channel record starts, followed by
+	// the end of the channel list. It is nil until the first walk and is
+	// cleared by writes that could move a record.
+	channelOffsets []int64
+	// model caches the result of Model. It is nil until first read and is
+	// cleared by writes that touch the model bytes.
+	model *Model
+	// radioIDOffset caches where the radio ID section starts. It is zero
+	// until first found and is cleared by writes at or before it.
+	radioIDOffset int64
 }
 
+// MutationHook is told about every change made to the codeplug: the offset,
+// the bytes that were there before and the bytes written. old is shorter
+// than new when a write extends the file, and new is empty when the file is
+// truncated.
+type MutationHook func(offset int64, old, new []byte)
+
 type Info struct {
-	Model          string
-	RadioIDs       []int
-	RadioIDIndices []int
+	Model          string `json:"model"`
+	ChannelCount   int    `json:"channel_count"`
+	RadioIDs       []int  `json:"radio_ids"`
+	RadioIDIndices []int  `json:"radio_id_indices"`
+}
+
+// InfoOptions selects which parts of Info are read. Locating the radio IDs
+// means walking every channel record, so callers that do not need them can
+// skip that work.
+type InfoOptions struct {
+	SkipRadioIDs bool
 }
 
 func Open(path string) (*Codeplug, error) {
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	return openFile(path, os.O_RDWR)
+}
+
+// OpenReadOnly opens a codeplug for reading. It takes a shared lock, so any
+// number of readers can run together but not alongside a writer, and every
+// write fails.
+func OpenReadOnly(path string) (*Codeplug, error) {
+	return openFile(path, os.O_RDONLY)
+}
+
+func openFile(path string, flag int) (*Codeplug, error) {
+	file, err := os.OpenFile(path, flag, 0644)
 	if err != nil {
+		if isLockError(err) {
+			return nil, ErrFileLocked
+		}
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
+	// Writing while another program (typically CPS) has the file open
+	// corrupts it, so writers hold an exclusive lock for the lifetime of the
+	// handle and readers a shared one.
+	if err := lockFile(file, flag == os.O_RDWR); err != nil {
+		file.Close()
+		if errors.Is(err, ErrFileLocked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock file: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if stat.Size() < headerSize {
+		file.Close()
+		return nil, fmt.Errorf("%w: %d bytes, expected at least %d", ErrFileTooSmall, stat.Size(), headerSize)
+	}
+
 	return &Codeplug{
+		rw:   file,
 		file: file,
 		path: path,
 	}, nil
 }
 
 func (cp *Codeplug) Close() error {
-	return cp.file.Close()
+	if cp.file != nil {
+		return cp.file.Close()
+	}
+	return nil
+}
+
+// SetVerifyWrites makes every write sync the file and read the range back,
+// failing if the bytes on disk differ from what was written. It guards
+// against media that silently drop writes at the cost of doubling IO.
+func (cp *Codeplug) SetVerifyWrites(verify bool) {
+	cp.verifyWrites = verify
+}
+
+// SetLogger sets where the reader reports recoveries such as falling back
+// to a signature scan. Nothing is logged when no logger is set.
+func (cp *Codeplug) SetLogger(logger *log.Logger) {
+	cp.logger = logger
+}
+
+// SetMutationHook registers fn to be called after every successful write
+// or truncation, for building edit logs, undo or change previews. It is
+// called synchronously, before the write returns; pass nil to remove it.
+func (cp *Codeplug) SetMutationHook(fn MutationHook) {
+	cp.onMutation = fn
+}
+
+func (cp *Codeplug) logf(format string, args ...any) {
+	if cp.logger != nil {
+		cp.logger.Printf(format, args...)
+	}
+}
+
+// writeAt writes data at offset. Writes before the end of the channel list
+// may change record lengths, so they drop the cached channel offsets; use
+// writeFieldAt for fixed-size fields inside a record.
+func (cp *Codeplug) writeAt(data []byte, offset int64) (int, error) {
+	cp.invalidateChannelOffsets(offset)
+	return cp.writeFieldAt(data, offset)
+}
+
+func (cp *Codeplug) writeFieldAt(data []byte, offset int64) (int, error) {
+	var old []byte
+	if cp.onMutation != nil {
+		old = make([]byte, len(data))
+		n, err := cp.readUpTo(old, offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("failed to read %d bytes at offset %d: %w", len(data), offset, err)
+		}
+		old = old[:n]
+	}
+
+	cp.invalidateModel(offset, int64(len(data)))
+	cp.invalidateRadioIDOffset(offset)
+	n, err := cp.rw.WriteAt(data, offset)
+	if err != nil {
+		return n, err
+	}
+	if cp.onMutation != nil {
+		cp.onMutation(offset, old, append([]byte(nil), data...))
+	}
+	if !cp.verifyWrites {
+		return n, nil
+	}
+
+	if err := cp.sync(); err != nil {
+		return n, fmt.Errorf("failed to sync file: %w", err)
+	}
+
+	readBack := make([]byte, len(data))
+	if err := cp.readAt(readBack, offset); err != nil {
+		return n, fmt.Errorf("failed to read back %d bytes at offset %d: %w", len(data), offset, err)
+	}
+	if !bytes.Equal(readBack, data) {
+		return n, fmt.Errorf("write verification failed: %d bytes at offset %d did not read back as written", len(data), offset)
+	}
+
+	return n, nil
 }
 
 func getSafeByteValue(data []byte, index int) byte {
@@ -47,10 +211,45 @@ func getSafeByteValue(data []byte, index int) byte {
 	return 0
 }
 
+func (cp *Codeplug) channelCount() (int, error) {
+	channelCountBuf := make([]byte, 1)
+	if err := cp.readAt(channelCountBuf, totalChannelsAddress); err != nil {
+		return 0, fmt.Errorf("failed to read total channels: %w", err)
+	}
+	return int(channelCountBuf[0]), nil
+}
+
+func (cp *Codeplug) setChannelCount(count int) error {
+	if count < 0 || count > maxChannelCount {
+		return fmt.Errorf("channel count %d does not fit in the count byte", count)
+	}
+	if _, err := cp.writeAt([]byte{byte(count)}, totalChannelsAddress); err != nil {
+		return fmt.Errorf("failed to write total channels: %w", err)
+	}
+	return nil
+}
+
 func (cp *Codeplug) GetInfo() (*Info, error) {
-	model := make([]byte, modelSize)
-	if _, err := cp.file.ReadAt(model, modelOffset); err != nil {
-		return nil, fmt.Errorf("failed to read model: %w", err)
+	return cp.GetInfoWith(InfoOptions{})
+}
+
+func (cp *Codeplug) GetInfoWith(opts InfoOptions) (*Info, error) {
+	model, err := cp.readModel()
+	if err != nil {
+		return nil, err
+	}
+
+	channelCount, err := cp.channelCount()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{
+		Model:        strings.TrimRight(model, "\x00 "),
+		ChannelCount: channelCount,
+	}
+	if opts.SkipRadioIDs {
+		return info, nil
 	}
 
 	radioIDs, err := cp.GetRadioIDs()
@@ -58,16 +257,195 @@ func (cp *Codeplug) GetInfo() (*Info, error) {
 		return nil, fmt.Errorf("failed to get radio IDs: %w", err)
 	}
 
-	ids := make([]int, len(radioIDs))
-	indices := make([]int, len(radioIDs))
+	info.RadioIDs = make([]int, len(radioIDs))
+	info.RadioIDIndices = make([]int, len(radioIDs))
 	for i, entry := range radioIDs {
-		ids[i] = entry.ID
-		indices[i] = entry.Index
+		info.RadioIDs[i] = entry.ID
+		info.RadioIDIndices[i] = entry.Index
 	}
 
-	return &Info{
-		Model:          string(model),
-		RadioIDs:       ids,
-		RadioIDIndices: indices,
-	}, nil
+	return info, nil
+}
+
+func (cp *Codeplug) Snapshot() ([]byte, error) {
+	size, err := cp.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, size)
+	if err := cp.readAt(data, 0); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+func (cp *Codeplug) Restore(data []byte) error {
+	if _, err := cp.writeAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := cp.truncate(int64(len(data))); err != nil {
+		return fmt.Errorf("failed to truncate file: %w", err)
+	}
+
+	return nil
+}
+
+func (cp *Codeplug) Size() (int64, error) {
+	if cp.file == nil {
+		if s, ok := cp.rw.(sizer); ok {
+			return s.Size(), nil
+		}
+		return 0, fmt.Errorf("storage size is unknown")
+	}
+
+	stat, err := cp.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return stat.Size(), nil
+}
+
+// Backup writes a copy of the codeplug next to it, named after the file with
+// a millisecond timestamp. An existing backup is never overwritten: if the
+// name is taken, a numeric suffix is added.
+func (cp *Codeplug) Backup() (string, error) {
+	if cp.path == "" {
+		return "", fmt.Errorf("cannot back up a codeplug that was not opened from a file")
+	}
+
+	data, err := cp.Snapshot()
+	if err != nil {
+		return "", err
+	}
+
+	base := fmt.Sprintf("%s.%s", cp.path, time.Now().Format("20060102-150405.000"))
+	for n := 1; n <= maxBackupAttempts; n++ {
+		backupPath := base + ".bak"
+		if n > 1 {
+			backupPath = fmt.Sprintf("%s-%d.bak", base, n)
+		}
+
+		file, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create backup: %w", err)
+		}
+
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(backupPath)
+			return "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		return backupPath, nil
+	}
+
+	return "", fmt.Errorf("failed to create backup: %d backups named %s already exist", maxBackupAttempts, base)
+}
+
+func (cp *Codeplug) checkRange(offset int64, length int) error {
+	size, err := cp.Size()
+	if err != nil {
+		return err
+	}
+
+	if offset < 0 || length < 0 || offset+int64(length) > size {
+		return fmt.Errorf("range %d+%d is outside the file (size %d)", offset, length, size)
+	}
+
+	return nil
+}
+
+func (cp *Codeplug) WriteRaw(offset int64, data []byte) error {
+	if err := cp.checkRange(offset, len(data)); err != nil {
+		return err
+	}
+
+	if _, err := cp.writeAt(data, offset); err != nil {
+		return fmt.Errorf("failed to write at offset %d: %w", offset, err)
+	}
+
+	return nil
+}
+
+func (cp *Codeplug) ReadRaw(offset int64, length int) ([]byte, error) {
+	if err := cp.checkRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, length)
+	if err := cp.readAt(data, offset); err != nil {
+		return nil, fmt.Errorf("failed to read at offset %d: %w", offset, err)
+	}
+
+	return data, nil
+}
+
+func (cp *Codeplug) CheckWritable() error {
+	buf := make([]byte, 1)
+	if err := cp.readAt(buf, 0); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if _, err := cp.writeAt(buf, 0); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := cp.sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+
+	return nil
+}
+
+// insertBytes writes data at offset, moving everything from offset to the end
+// of the file towards the end to make room.
+func (cp *Codeplug) insertBytes(offset int64, data []byte) error {
+	return cp.replaceBytes(offset, 0, data)
+}
+
+// removeBytes deletes length bytes at offset, moving the rest of the file
+// up and truncating it.
+func (cp *Codeplug) removeBytes(offset int64, length int) error {
+	return cp.replaceBytes(offset, length, nil)
+}
+
+// replaceBytes swaps the length bytes at offset for data, moving the rest of
+// the file to match. The tail is rewritten once, so a failed replacement
+// never leaves it moved by only part of the difference.
+func (cp *Codeplug) replaceBytes(offset int64, length int, data []byte) error {
+	if err := cp.checkRange(offset, length); err != nil {
+		return err
+	}
+
+	size, err := cp.Size()
+	if err != nil {
+		return err
+	}
+
+	tailOffset := offset + int64(length)
+	tail := make([]byte, size-tailOffset)
+	if err := cp.readAt(tail, tailOffset); err != nil {
+		return fmt.Errorf("failed to read at offset %d: %w", tailOffset, err)
+	}
+
+	replacement := append(append([]byte(nil), data...), tail...)
+	if _, err := cp.writeAt(replacement, offset); err != nil {
+		return fmt.Errorf("failed to write at offset %d: %w", offset, err)
+	}
+
+	if newSize := offset + int64(len(replacement)); newSize < size {
+		if err := cp.truncate(newSize); err != nil {
+			return fmt.Errorf("failed to truncate file: %w", err)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,135 @@
+package codeplug
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testChannel struct {
+	name        string
+	rx, tx      uint32
+	channelType byte
+}
+
+// channelRecord encodes a minimal D878 channel record: the header fields
+// this package decodes, the null-terminated name and zeroed trailing bytes.
+func channelRecord(c testChannel) []byte {
+	header := make([]byte, channelHeaderSize)
+	binary.LittleEndian.PutUint32(header[headerRxFreqOffset:], c.rx)
+	binary.LittleEndian.PutUint32(header[headerTxFreqOffset:], c.tx)
+	header[headerChannelTypeOffset] = c.channelType
+	header[headerTxPowerOffset] = TxPowerHigh
+	header[headerBandwidthOffset] = Bandwidth25
+	header[headerScanListOffset] = 0xFF
+
+	record := append(header, c.name...)
+	record = append(record, 0)
+	return append(record, make([]byte, channelTrailingSize)...)
+}
+
+func radioIDRecord(entry RadioIDEntry) []byte {
+	record := []byte{byte(entry.Index), byte(entry.ID), byte(entry.ID >> 8), byte(entry.ID >> 16)}
+	record = append(record, entry.Name...)
+	return append(record, 0)
+}
+
+// buildCodeplug lays out a D878UV2 file: the header with the model and
+// channel count, the channel records, the gap before the radio IDs, the
+// radio ID entries and some zero padding as found in real files.
+func buildCodeplug(channels []testChannel, radioIDs []RadioIDEntry) []byte {
+	data := make([]byte, d878ChannelsOffset)
+	copy(data[modelOffset:], "D878UV2")
+	data[totalChannelsAddress] = byte(len(channels))
+
+	for _, c := range channels {
+		data = append(data, channelRecord(c)...)
+	}
+	data = append(data, make([]byte, radioIDSectionGap)...)
+	for _, entry := range radioIDs {
+		data = append(data, radioIDRecord(entry)...)
+	}
+	return append(data, make([]byte, 256)...)
+}
+
+var (
+	testChannels = []testChannel{
+		{name: "Simplex 1", rx: mhz(146.52), tx: mhz(146.52)},
+		{name: "W1AW Rptr", rx: mhz(146.94), tx: mhz(146.34)},
+		{name: "DMR Local", rx: mhz(445), tx: mhz(445), channelType: ChannelTypeDigital},
+		{name: "GMRS 1", rx: mhz(462.5625), tx: mhz(462.5625)},
+	}
+	testRadioIDs = []RadioIDEntry{
+		{Index: 0, ID: 3161234, Name: "Radio ID 1"},
+		{Index: 1, ID: 3165678, Name: "Radio ID 2"},
+	}
+)
+
+func newTestCodeplug(t *testing.T) *Codeplug {
+	t.Helper()
+	return NewFromBytes(buildCodeplug(testChannels, testRadioIDs))
+}
+
+// checkCodeplug re-reads every channel and radio ID and compares them with
+// what is expected, failing on the first difference.
+func checkCodeplug(t *testing.T, cp *Codeplug, channels []testChannel, radioIDs []RadioIDEntry) {
+	t.Helper()
+
+	got, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels: %v", err)
+	}
+	if len(got) != len(channels) {
+		t.Fatalf("got %d channels, want %d", len(got), len(channels))
+	}
+	for i, want := range channels {
+		if got[i].Name != want.name || got[i].RxFreq != want.rx || uint32(got[i].TxFreq) != want.tx {
+			t.Errorf("channel %d = %q %d/%d, want %q %d/%d", i, got[i].Name, got[i].RxFreq, got[i].TxFreq, want.name, want.rx, want.tx)
+		}
+	}
+
+	entries, err := cp.GetRadioIDs()
+	if err != nil {
+		t.Fatalf("GetRadioIDs: %v", err)
+	}
+	if len(entries) != len(radioIDs) {
+		t.Fatalf("got %d radio IDs, want %d", len(entries), len(radioIDs))
+	}
+	for i, want := range radioIDs {
+		if entries[i].Index != want.Index || entries[i].ID != want.ID || entries[i].Name != want.Name {
+			t.Errorf("radio ID %d = %d %d %q, want %d %d %q", i, entries[i].Index, entries[i].ID, entries[i].Name, want.Index, want.ID, want.Name)
+		}
+	}
+}
+
+func TestBackupNeverOverwrites(t *testing.T) {
+	data := buildCodeplug(testChannels, testRadioIDs)
+	path := filepath.Join(t.TempDir(), "codeplug.rdt")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cp, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer cp.Close()
+
+	seen := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		backupPath, err := cp.Backup()
+		if err != nil {
+			t.Fatalf("Backup: %v", err)
+		}
+		if seen[backupPath] {
+			t.Fatalf("Backup reused %s", backupPath)
+		}
+		seen[backupPath] = true
+
+		backup, err := os.ReadFile(backupPath)
+		if err != nil || !bytes.Equal(backup, data) {
+			t.Fatalf("backup %s does not match the codeplug: %v", backupPath, err)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package codeplug
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type csvColumn struct {
+	Name   string
+	Format func(c *Channel) string
+	// Parse stores value in c, or fails if it is not valid for model. It
+	// is nil for columns import does not change.
+	Parse func(c *Channel, value string, model Model) error
+}
+
+// channelCSVColumns is the column layout written by ExportChannelsCSV and
+// read by ImportChannelsCSV. Frequencies are exact decimal MHz, tones are
+// decoded as in get channel and flags are on/off; the other fields are the
+// stored values.
+var channelCSVColumns = []csvColumn{
+	{"index", func(c *Channel) string { return strconv.Itoa(c.Index) }, nil},
+	{"name", func(c *Channel) string { return c.Name }, func(c *Channel, value string, model Model) error {
+		if value == "" {
+			return fmt.Errorf("channel name cannot be empty")
+		}
+		if problems := model.ChannelNameProblems(value); len(problems) > 0 {
+			return fmt.Errorf("invalid channel name %q: %s", value, strings.Join(problems, ", "))
+		}
+		c.Name = value
+		return nil
+	}},
+	{"rx-freq", func(c *Channel) string { return FormatMHz(int64(c.RxFreq), true) }, func(c *Channel, value string, model Model) error {
+		freq, err := parseCSVFreq(value, model)
+		c.RxFreq = freq
+		return err
+	}},
+	{"tx-freq", func(c *Channel) string { return FormatMHz(int64(c.TxFreq), true) }, func(c *Channel, value string, model Model) error {
+		freq, err := parseCSVFreq(value, model)
+		c.TxFreq = int32(freq)
+		return err
+	}},
+	{"type", func(c *Channel) string { return byteString(c.ChannelType) }, byteParser(func(c *Channel) *byte { return &c.ChannelType }, ChannelTypeDigitalAnalog)},
+	{"power", func(c *Channel) string { return byteString(c.TxPower) }, byteParser(func(c *Channel) *byte { return &c.TxPower }, 3)},
+	{"bandwidth", func(c *Channel) string { return byteString(c.Bandwidth) }, byteParser(func(c *Channel) *byte { return &c.Bandwidth }, Bandwidth25)},
+	{"rx-tone", func(c *Channel) string { return c.DecodeRxTone() }, toneParser(func(c *Channel) (*byte, *byte) { return &c.CtcssDcsDecode, &c.CtcssDcsDecodeOption })},
+	{"tx-tone", func(c *Channel) string { return c.DecodeTxTone() }, toneParser(func(c *Channel) (*byte, *byte) { return &c.CtcssDcsEncode, &c.CtcssDcsEncodeOption })},
+	{"color-code", func(c *Channel) string { return byteString(c.RxColorCode) }, byteParser(func(c *Channel) *byte { return &c.RxColorCode }, 15)},
+	{"slot", func(c *Channel) string { return byteString(c.Slot) }, byteParser(func(c *Channel) *byte { return &c.Slot }, 1)},
+	{"scan-list", func(c *Channel) string { return strconv.Itoa(int(c.ScanList)) }, func(c *Channel, value string, model Model) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < -1 || n > math.MaxInt8 {
+			return fmt.Errorf("invalid scan-list %q: use -1 for none or 0-%d", value, math.MaxInt8)
+		}
+		c.ScanList = int8(n)
+		return nil
+	}},
+	{"sms-confirmation", func(c *Channel) string { return onOffString(c.SmsConfirmation) }, onOffParser(func(c *Channel) *byte { return &c.SmsConfirmation })},
+	{"sms-forbid", func(c *Channel) string { return onOffString(c.SmsForbid) }, onOffParser(func(c *Channel) *byte { return &c.SmsForbid })},
+	{"data-ack-disable", func(c *Channel) string { return onOffString(c.DataAckDisable) }, onOffParser(func(c *Channel) *byte { return &c.DataAckDisable })},
+}
+
+func byteParser(field func(c *Channel) *byte, max byte) func(c *Channel, value string, model Model) error {
+	return func(c *Channel, value string, model Model) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > int(max) {
+			return fmt.Errorf("invalid value %q: use 0-%d", value, max)
+		}
+		*field(c) = byte(n)
+		return nil
+	}
+}
+
+func toneParser(field func(c *Channel) (index, option *byte)) func(c *Channel, value string, model Model) error {
+	return func(c *Channel, value string, model Model) error {
+		option, toneIndex, err := ParseTone(value)
+		if err != nil {
+			return err
+		}
+		index, opt := field(c)
+		*index, *opt = toneIndex, option
+		return nil
+	}
+}
+
+func onOffParser(field func(c *Channel) *byte) func(c *Channel, value string, model Model) error {
+	return func(c *Channel, value string, model Model) error {
+		switch strings.ToLower(value) {
+		case "on":
+			*field(c) = 1
+		case "off":
+			*field(c) = 0
+		default:
+			return fmt.Errorf("invalid value %q: use on or off", value)
+		}
+		return nil
+	}
+}
+
+func onOffString(b byte) string {
+	if b == 0 {
+		return "off"
+	}
+	return "on"
+}
+
+func parseCSVFreq(value string, model Model) (uint32, error) {
+	mhz, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid frequency %q", value)
+	}
+	freq, err := MHzToFreq(mhz)
+	if err != nil {
+		return 0, err
+	}
+	return freq, model.CheckFrequency(freq)
+}
+
+// CSVOptions selects the columns ExportChannelsCSVWith writes, in order. All
+// columns are written when Columns is empty.
+type CSVOptions struct {
+	Columns []string
+}
+
+// ChannelCSVColumns returns the names of every channel CSV column in the
+// order they are exported.
+func ChannelCSVColumns() []string {
+	names := make([]string, len(channelCSVColumns))
+	for i, col := range channelCSVColumns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// ExportChannelsCSV writes a header row and one row per channel. Fields
+// containing commas or quotes are quoted.
+func ExportChannelsCSV(w io.Writer, channels []*Channel) error {
+	return ExportChannelsCSVWith(w, channels, CSVOptions{})
+}
+
+func ExportChannelsCSVWith(w io.Writer, channels []*Channel, opts CSVOptions) error {
+	columns, err := selectCSVColumns(opts.Columns)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	row := make([]string, len(columns))
+	for _, channel := range channels {
+		for i, col := range columns {
+			row[i] = col.Format(channel)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write channel %d: %w", channel.Index, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvTemplateChannel is the example row written by WriteChannelsCSVTemplate:
+// a 2 m simplex channel with a 100.0 Hz tone and no scan list.
+var csvTemplateChannel = Channel{
+	Index:       0,
+	Name:        "Simplex 1",
+	RxFreq:      mhz(146.52),
+	TxFreq:      int32(mhz(146.52)),
+	ChannelType: ChannelTypeAnalog,
+	TxPower:     TxPowerHigh,
+	Bandwidth:   Bandwidth25,
+	// 100.0 Hz is entry 13 of CTCSSTones.
+	CtcssDcsDecodeOption: ToneCTCSS,
+	CtcssDcsDecode:       13,
+	CtcssDcsEncodeOption: ToneCTCSS,
+	CtcssDcsEncode:       13,
+	RxColorCode:          1,
+	ScanList:             -1,
+}
+
+// WriteChannelsCSVTemplate writes the channel CSV header and one example
+// row, formatted by the same columns as ExportChannelsCSV.
+func WriteChannelsCSVTemplate(w io.Writer) error {
+	return ExportChannelsCSV(w, []*Channel{&csvTemplateChannel})
+}
+
+// RowError is an import row that could not be applied. Index is -1 when the
+// row's channel index could not be read.
+type RowError struct {
+	Line  int
+	Index int
+	Err   error
+}
+
+func (e RowError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d (channel %d): %v", e.Line, e.Index, e.Err)
+}
+
+type ImportResult struct {
+	Added     int
+	Updated   int
+	Unchanged int
+	Errors    []RowError
+}
+
+// ChannelImport is a CSV import that has been read and validated but not yet
+// written. Apply writes it; nothing is written while Errors is non-empty.
+// Skipped lists rows that were valid but describe something the radio
+// cannot store; they are reported and left out of Updates.
+type ChannelImport struct {
+	Updates []ChannelUpdate
+	Errors  []RowError
+	Skipped []RowError
+}
+
+// ChannelUpdate is one CSV row: the channel as it is now and as it will be
+// once the row is applied. For a New channel, Before is the placeholder
+// channel that is appended before the row is written to it.
+type ChannelUpdate struct {
+	Line   int
+	New    bool
+	Before *Channel
+	After  Channel
+}
+
+// Changed reports whether applying the row would change the channel.
+func (u ChannelUpdate) Changed() bool {
+	if u.New || u.Before.TxFreqDirection != u.After.TxFreqDirection {
+		return true
+	}
+	for _, col := range channelCSVColumns {
+		if col.Parse != nil && col.Format(u.Before) != col.Format(&u.After) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportChannelsCSV applies a CSV in the ExportChannelsCSV layout to the
+// channels named by its index column. Any subset of columns may be present
+// as long as index is one of them. Every row is validated before anything is
+// written: if any row fails, the errors are returned in the result and the
+// codeplug is left untouched.
+func ImportChannelsCSV(cp *Codeplug, r io.Reader) (*ImportResult, error) {
+	plan, err := PlanChannelsCSV(cp, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Errors) > 0 {
+		return &ImportResult{Errors: plan.Errors}, nil
+	}
+	return plan.Apply(cp)
+}
+
+// PlanChannelsCSV reads and validates a channel CSV without writing
+// anything. Every row is checked, so all problems are reported at once.
+func PlanChannelsCSV(cp *Codeplug, r io.Reader) (*ChannelImport, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make([]csvColumn, len(header))
+	indexColumn := -1
+	for i, name := range header {
+		col, ok := lookupCSVColumn(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		if col.Name == "index" {
+			indexColumn = i
+		}
+		columns[i] = col
+	}
+	if indexColumn < 0 {
+		return nil, fmt.Errorf("CSV has no index column")
+	}
+
+	plan := &ChannelImport{}
+	seen := make(map[int]int)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// A row that failed to parse has no field positions, so the
+			// line comes from the parse error.
+			var line int
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line, err = parseErr.StartLine, parseErr.Err
+			}
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: -1, Err: err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		index, err := strconv.Atoi(strings.TrimSpace(row[indexColumn]))
+		if err != nil {
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: -1, Err: fmt.Errorf("invalid index %q", row[indexColumn])})
+			continue
+		}
+		if first, ok := seen[index]; ok {
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: index, Err: fmt.Errorf("channel already updated on line %d", first)})
+			continue
+		}
+		seen[index] = line
+
+		update, err := cp.planChannelRow(index, columns, row, model)
+		if err != nil {
+			plan.Errors = append(plan.Errors, RowError{Line: line, Index: index, Err: err})
+			continue
+		}
+		update.Line = line
+		plan.Updates = append(plan.Updates, update)
+	}
+
+	return plan, nil
+}
+
+// Apply writes every update in the plan. If a write fails the codeplug is
+// restored to how it was before Apply was called.
+func (plan *ChannelImport) Apply(cp *Codeplug) (*ImportResult, error) {
+	if len(plan.Errors) > 0 {
+		return nil, fmt.Errorf("import has %d invalid rows", len(plan.Errors))
+	}
+
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+	original, err := cp.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	for _, update := range plan.Updates {
+		var err error
+		if update.New {
+			err = cp.appendPlaceholderChannel(update.Before.Index, model)
+		}
+
+		// Renames move the records after them, so each channel is read
+		// again rather than trusting the offsets from planning.
+		var before *Channel
+		if err == nil {
+			before, err = cp.GetChannelByIndex(update.Before.Index)
+		}
+		if err == nil {
+			var changed bool
+			changed, err = cp.writeChannelChanges(before, &update.After, model)
+			switch {
+			case update.New:
+				result.Added++
+			case changed:
+				result.Updated++
+			default:
+				result.Unchanged++
+			}
+		}
+		if err != nil {
+			if restoreErr := cp.Restore(original); restoreErr != nil {
+				return nil, fmt.Errorf("line %d (channel %d): %w (rollback failed: %v)", update.Line, update.Before.Index, err, restoreErr)
+			}
+			return nil, fmt.Errorf("line %d (channel %d): %w (no changes were saved)", update.Line, update.Before.Index, err)
+		}
+	}
+
+	return result, nil
+}
+
+// appendPlaceholderChannel adds a placeholder channel at index, which must
+// be the channel count. Like ResetChannel it keeps the leading header bytes
+// this package does not decode, copied from the channel before it.
+func (cp *Codeplug) appendPlaceholderChannel(index int, model Model) error {
+	record, err := placeholderChannelRecord(model, index)
+	if err != nil {
+		return err
+	}
+	if index > 0 {
+		previous, err := cp.GetChannelByIndex(index - 1)
+		if err != nil {
+			return err
+		}
+		if err := cp.readAt(record[:headerRxFreqOffset], previous.Offset); err != nil {
+			return fmt.Errorf("failed to read channel header at offset %d: %w", previous.Offset, err)
+		}
+	}
+	return cp.insertChannelRecord(index, record)
+}
+
+// CheckCSVColumns reports the first name that is not a channel CSV column.
+func CheckCSVColumns(names []string) error {
+	_, err := selectCSVColumns(names)
+	return err
+}
+
+func selectCSVColumns(names []string) ([]csvColumn, error) {
+	if len(names) == 0 {
+		return channelCSVColumns, nil
+	}
+
+	columns := make([]csvColumn, len(names))
+	for i, name := range names {
+		col, ok := lookupCSVColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown CSV column %q: use %s", name, strings.Join(ChannelCSVColumns(), ", "))
+		}
+		columns[i] = col
+	}
+	return columns, nil
+}
+
+func lookupCSVColumn(name string) (csvColumn, bool) {
+	for _, col := range channelCSVColumns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return csvColumn{}, false
+}
+
+func (cp *Codeplug) planChannelRow(index int, columns []csvColumn, row []string, model Model) (ChannelUpdate, error) {
+	before, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return ChannelUpdate{}, err
+	}
+
+	after := *before
+	for i, col := range columns {
+		value := strings.TrimSpace(row[i])
+		if col.Parse == nil || value == col.Format(before) {
+			continue
+		}
+		if err := col.Parse(&after, value, model); err != nil {
+			return ChannelUpdate{}, fmt.Errorf("%s: %w", col.Name, err)
+		}
+	}
+
+	return ChannelUpdate{Before: before, After: after}, nil
+}
+
+// writeChannelChanges writes the fields import can change that differ
+// between before and after. The TX direction follows the frequencies:
+// simplex when they match, otherwise + or - unless it was independent.
+func (cp *Codeplug) writeChannelChanges(before, after *Channel, model Model) (bool, error) {
+	header := make([]byte, channelHeaderSize)
+	if err := cp.readAt(header, before.Offset); err != nil {
+		return false, fmt.Errorf("failed to read channel header at offset %d: %w", before.Offset, err)
+	}
+	original := append([]byte(nil), header...)
+
+	if after.RxFreq != before.RxFreq || after.TxFreq != before.TxFreq || after.TxFreqDirection != before.TxFreqDirection {
+		if err := model.FreqEncoding.Encode(header[headerRxFreqOffset:], after.RxFreq); err != nil {
+			return false, err
+		}
+		if err := model.FreqEncoding.Encode(header[headerTxFreqOffset:], uint32(after.TxFreq)); err != nil {
+			return false, err
+		}
+		switch {
+		case int64(after.TxFreq) == int64(after.RxFreq):
+			header[headerTxDirectionOffset] = TxDirectionSimplex
+		case after.TxFreqDirection == TxDirectionIndependent:
+			header[headerTxDirectionOffset] = TxDirectionIndependent
+		case int64(after.TxFreq) > int64(after.RxFreq):
+			header[headerTxDirectionOffset] = TxDirectionPlus
+		default:
+			header[headerTxDirectionOffset] = TxDirectionMinus
+		}
+	}
+
+	header[headerChannelTypeOffset] = after.ChannelType
+	header[headerTxPowerOffset] = after.TxPower
+	header[headerBandwidthOffset] = after.Bandwidth
+	header[headerRxToneOffset] = after.CtcssDcsDecode
+	header[headerRxToneOptionOffset] = after.CtcssDcsDecodeOption
+	header[headerTxToneOffset] = after.CtcssDcsEncode
+	header[headerTxToneOptionOffset] = after.CtcssDcsEncodeOption
+	header[headerScanListOffset] = byte(after.ScanList)
+	header[headerColorCodeOffset] = after.RxColorCode
+	header[headerSlotOffset] = after.Slot
+
+	changed := !bytes.Equal(header, original)
+	if changed {
+		if err := cp.writeHeader(before, 0, header); err != nil {
+			return false, err
+		}
+	}
+
+	for _, flag := range []struct {
+		offset        int
+		before, after byte
+	}{
+		{trailingSmsConfirmationOffset, before.SmsConfirmation, after.SmsConfirmation},
+		{trailingSmsForbidOffset, before.SmsForbid, after.SmsForbid},
+		{trailingDataAckDisableOffset, before.DataAckDisable, after.DataAckDisable},
+	} {
+		if flag.after == flag.before {
+			continue
+		}
+		if err := cp.writeTrailingByte(before, flag.offset, flag.after); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	// The name goes last since a new length moves everything after it.
+	if after.Name != before.Name {
+		if err := cp.SetChannelName(before.Index, after.Name); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,147 @@
+package codeplug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportChannelsCSVIsAllOrNothing(t *testing.T) {
+	cp := newTestCodeplug(t)
+	original, err := cp.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	csv := "index,name,rx-freq\n0,Renamed Channel,146.55\n1,Beta,999\n"
+	result, err := ImportChannelsCSV(cp, strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ImportChannelsCSV: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 3 || result.Updated != 0 {
+		t.Fatalf("result = %+v, want one error on line 3 and no updates", result)
+	}
+
+	after, err := cp.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if !bytes.Equal(after, original) {
+		t.Error("a rejected import changed the codeplug")
+	}
+
+	csv = "index,name,rx-freq\n0,Renamed Channel,146.55\n2,D,445.5\n"
+	result, err = ImportChannelsCSV(cp, strings.NewReader(csv))
+	if err != nil || len(result.Errors) != 0 || result.Updated != 2 {
+		t.Fatalf("ImportChannelsCSV = %+v, %v", result, err)
+	}
+
+	want := append([]testChannel(nil), testChannels...)
+	want[0].name, want[0].rx = "Renamed Channel", mhz(146.55)
+	want[2].name, want[2].rx = "D", mhz(445.5)
+	checkCodeplug(t, cp, want, testRadioIDs)
+}
+
+func TestPlanChannelsCSVMalformedRow(t *testing.T) {
+	cp := newTestCodeplug(t)
+	csv := "index,name\n0,Renamed\n1\"x,Beta\n"
+	plan, err := PlanChannelsCSV(cp, strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("PlanChannelsCSV: %v", err)
+	}
+	if len(plan.Errors) != 1 || plan.Errors[0].Line != 3 {
+		t.Fatalf("errors = %v, want one error on line 3", plan.Errors)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].After.Name != "Renamed" {
+		t.Errorf("got %d updates, want channel 0 renamed", len(plan.Updates))
+	}
+}
+
+func TestChannelsCSVRoundTrip(t *testing.T) {
+	cp := newTestCodeplug(t)
+	if err := cp.SetChannelTones(1, "100.0", "D754I"); err != nil {
+		t.Fatalf("SetChannelTones: %v", err)
+	}
+	original, err := cp.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	channels, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels: %v", err)
+	}
+
+	var exported bytes.Buffer
+	if err := ExportChannelsCSV(&exported, channels); err != nil {
+		t.Fatalf("ExportChannelsCSV: %v", err)
+	}
+	if !strings.Contains(exported.String(), "100.0 Hz,D754I") {
+		t.Errorf("export does not contain the decoded tones:\n%s", exported.String())
+	}
+
+	// Importing the export unchanged must not write anything.
+	result, err := ImportChannelsCSV(cp, bytes.NewReader(exported.Bytes()))
+	if err != nil || len(result.Errors) != 0 || result.Updated != 0 {
+		t.Fatalf("re-import = %+v, %v", result, err)
+	}
+	if after, _ := cp.Snapshot(); !bytes.Equal(after, original) {
+		t.Error("re-importing an unchanged export changed the codeplug")
+	}
+
+	csv := "index,rx-tone,tx-tone,sms-confirmation,data-ack-disable\n2,D023N,88.5,on,on\n"
+	if result, err := ImportChannelsCSV(cp, strings.NewReader(csv)); err != nil || len(result.Errors) != 0 {
+		t.Fatalf("ImportChannelsCSV = %+v, %v", result, err)
+	}
+	channel, err := cp.GetChannelByIndex(2)
+	if err != nil {
+		t.Fatalf("GetChannelByIndex: %v", err)
+	}
+	if channel.DecodeRxTone() != "D023N" || channel.DecodeTxTone() != "88.5 Hz" || channel.SmsConfirmation != 1 || channel.DataAckDisable != 1 || channel.SmsForbid != 0 {
+		t.Errorf("channel 2 = %s/%s, sms %d/%d, data ack %d", channel.DecodeRxTone(), channel.DecodeTxTone(), channel.SmsConfirmation, channel.SmsForbid, channel.DataAckDisable)
+	}
+	checkCodeplug(t, cp, testChannels, testRadioIDs)
+}
+
+func TestExportChannelsCSVColumns(t *testing.T) {
+	cp := newTestCodeplug(t)
+	channels, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := ExportChannelsCSVWith(&out, channels[:2], CSVOptions{Columns: []string{"name", "rx-freq", "index"}}); err != nil {
+		t.Fatalf("ExportChannelsCSVWith: %v", err)
+	}
+	want := "name,rx-freq,index\nSimplex 1,146.52,0\nW1AW Rptr,146.94,1\n"
+	if out.String() != want {
+		t.Errorf("export = %q, want %q", out.String(), want)
+	}
+
+	if err := ExportChannelsCSVWith(&out, channels, CSVOptions{Columns: []string{"name", "rx"}}); err == nil {
+		t.Error("ExportChannelsCSVWith accepted an unknown column")
+	}
+}
+
+func TestChannelsCSVTemplateImports(t *testing.T) {
+	var template bytes.Buffer
+	if err := WriteChannelsCSVTemplate(&template); err != nil {
+		t.Fatalf("WriteChannelsCSVTemplate: %v", err)
+	}
+	header, _, _ := strings.Cut(template.String(), "\n")
+	if header != strings.Join(ChannelCSVColumns(), ",") {
+		t.Errorf("template header = %q, want the export columns", header)
+	}
+
+	cp := newTestCodeplug(t)
+	plan, err := PlanChannelsCSV(cp, &template)
+	if err != nil {
+		t.Fatalf("PlanChannelsCSV: %v", err)
+	}
+	if len(plan.Errors) > 0 {
+		t.Fatalf("template row rejected: %v", plan.Errors[0])
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].After.DecodeTxTone() != "100.0 Hz" {
+		t.Errorf("template planned %d updates, want channel 0 with a 100.0 Hz tone", len(plan.Updates))
+	}
+}
@@ -0,0 +1,63 @@
+package codeplug
+
+type DiffStatus int
+
+const (
+	DiffChanged DiffStatus = iota
+	DiffAdded
+	DiffRemoved
+)
+
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+type ChannelDiff struct {
+	Index   int
+	Name    string
+	Status  DiffStatus
+	Changes []FieldChange
+}
+
+func DiffChannels(oldChannels, newChannels []*Channel, fieldNames []string) ([]ChannelDiff, error) {
+	fields, err := LookupChannelFields(fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(oldChannels)
+	if len(newChannels) > total {
+		total = len(newChannels)
+	}
+
+	var diffs []ChannelDiff
+	for i := 0; i < total; i++ {
+		switch {
+		case i >= len(oldChannels):
+			diffs = append(diffs, ChannelDiff{Index: i, Name: newChannels[i].Name, Status: DiffAdded})
+		case i >= len(newChannels):
+			diffs = append(diffs, ChannelDiff{Index: i, Name: oldChannels[i].Name, Status: DiffRemoved})
+		default:
+			changes := compareChannel(oldChannels[i], newChannels[i], fields)
+			if len(changes) > 0 {
+				diffs = append(diffs, ChannelDiff{Index: i, Name: newChannels[i].Name, Status: DiffChanged, Changes: changes})
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+func compareChannel(oldChannel, newChannel *Channel, fields []ChannelField) []FieldChange {
+	var changes []FieldChange
+	for _, f := range fields {
+		oldValue := f.Format(oldChannel)
+		newValue := f.Format(newChannel)
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: f.Name, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
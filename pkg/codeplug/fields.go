@@ -0,0 +1,78 @@
+package codeplug
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type ChannelField struct {
+	Name   string
+	Format func(c *Channel) string
+}
+
+var ChannelFields = []ChannelField{
+	{"name", func(c *Channel) string { return c.Name }},
+	{"rx-freq", func(c *Channel) string { return FormatMHz(int64(c.RxFreq), false) }},
+	{"tx-freq", func(c *Channel) string { return FormatMHz(int64(c.TxFreq), false) }},
+	{"tx-direction", func(c *Channel) string { return c.TxDirectionLabel() }},
+	{"type", func(c *Channel) string { return c.ModeLabel() }},
+	{"power", func(c *Channel) string { return c.PowerLabel() }},
+	{"bandwidth", func(c *Channel) string { return c.BandwidthLabel() }},
+	{"ptt-prohibit", func(c *Channel) string { return byteString(c.PttProhibit) }},
+	{"call-confirmation", func(c *Channel) string { return byteString(c.CallConfirmation) }},
+	{"talkaround", func(c *Channel) string { return byteString(c.TalkAround) }},
+	{"rx-tone", func(c *Channel) string { return c.DecodeRxTone() }},
+	{"tx-tone", func(c *Channel) string { return c.DecodeTxTone() }},
+	{"contact", func(c *Channel) string { return byteString(c.Contact) }},
+	{"radio-id", func(c *Channel) string { return byteString(c.RadioId) }},
+	{"tx-permit", func(c *Channel) string { return byteString(c.TxPermit) }},
+	{"squelch-mode", func(c *Channel) string { return byteString(c.SquelchMode) }},
+	{"scan-list", func(c *Channel) string { return strconv.Itoa(int(c.ScanList)) }},
+	{"receive-group-list", func(c *Channel) string { return byteString(c.ReceiveGroupList) }},
+	{"color-code", func(c *Channel) string { return byteString(c.RxColorCode) }},
+	{"slot", func(c *Channel) string { return byteString(c.Slot) }},
+	{"slot-suit", func(c *Channel) string { return byteString(c.SlotSuit) }},
+	{"aprs-rx", func(c *Channel) string { return byteString(c.AprsRx) }},
+	{"aes-encryption-key", func(c *Channel) string { return byteString(c.AesEncryptionKey) }},
+	{"work-alone", func(c *Channel) string { return byteString(c.WorkAlone) }},
+	{"ranging", func(c *Channel) string { return byteString(c.Ranging) }},
+	{"correct-freq", func(c *Channel) string { return strconv.Itoa(int(c.CorrectFreq)) }},
+	{"sms-confirmation", func(c *Channel) string { return byteString(c.SmsConfirmation) }},
+	{"exclude-from-roaming", func(c *Channel) string { return byteString(c.ExcludeFromRoaming) }},
+	{"multiple-key", func(c *Channel) string { return byteString(c.MultipleKey) }},
+	{"random-key", func(c *Channel) string { return byteString(c.RandomKey) }},
+	{"sms-forbid", func(c *Channel) string { return byteString(c.SmsForbid) }},
+	{"data-ack-disable", func(c *Channel) string { return byteString(c.DataAckDisable) }},
+	{"auto-scan", func(c *Channel) string { return byteString(c.AutoScan) }},
+	{"send-talker-alias", func(c *Channel) string { return byteString(c.SendTalkerAlias) }},
+}
+
+func LookupChannelFields(names []string) ([]ChannelField, error) {
+	if len(names) == 0 {
+		return ChannelFields, nil
+	}
+
+	fields := make([]ChannelField, 0, len(names))
+	for _, name := range names {
+		field, ok := lookupChannelField(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown channel field: %s", name)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func lookupChannelField(name string) (ChannelField, bool) {
+	for _, f := range ChannelFields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return ChannelField{}, false
+}
+
+func byteString(b byte) string {
+	return strconv.Itoa(int(b))
+}
@@ -0,0 +1,112 @@
+package codeplug
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FreqScale is the number of codeplug frequency units per MHz. Frequencies
+// are stored as integers in 10 Hz steps.
+const FreqScale = 100000
+
+func FreqToMHz(freq int64) float64 {
+	return float64(freq) / FreqScale
+}
+
+func MHzToFreq(mhz float64) (uint32, error) {
+	freq := math.Round(mhz * FreqScale)
+	if freq < 0 || freq > math.MaxUint32 {
+		return 0, fmt.Errorf("frequency %g MHz out of range", mhz)
+	}
+	return uint32(freq), nil
+}
+
+func mhz(value float64) uint32 {
+	return uint32(math.Round(value * FreqScale))
+}
+
+// FormatMHz formats a frequency in MHz. With trimZeros, trailing zeros are
+// dropped from the exact integer representation instead of rounding to a
+// fixed number of decimals.
+func FormatMHz(freq int64, trimZeros bool) string {
+	if !trimZeros {
+		return fmt.Sprintf("%.4f", FreqToMHz(freq))
+	}
+
+	sign := ""
+	if freq < 0 {
+		sign = "-"
+		freq = -freq
+	}
+
+	decimals := 0
+	for s := FreqScale; s > 1; s /= 10 {
+		decimals++
+	}
+
+	fraction := strings.TrimRight(fmt.Sprintf("%0*d", decimals, freq%FreqScale), "0")
+	if fraction == "" {
+		fraction = "0"
+	}
+
+	return fmt.Sprintf("%s%d.%s", sign, freq/FreqScale, fraction)
+}
+
+// FreqEncoding is how a model stores a 4-byte frequency field.
+type FreqEncoding int
+
+const (
+	FreqEncodingLittleEndian FreqEncoding = iota
+	FreqEncodingBCD
+)
+
+func (e FreqEncoding) Decode(data []byte) (uint32, error) {
+	if len(data) < 4 {
+		return 0, fmt.Errorf("frequency field is %d bytes, expected 4", len(data))
+	}
+	if e == FreqEncodingBCD {
+		return DecodeBCD(data[:4])
+	}
+	return binary.LittleEndian.Uint32(data), nil
+}
+
+func (e FreqEncoding) Encode(dst []byte, freq uint32) error {
+	if len(dst) < 4 {
+		return fmt.Errorf("frequency field is %d bytes, expected 4", len(dst))
+	}
+	if e == FreqEncodingBCD {
+		return EncodeBCD(dst[:4], freq)
+	}
+	binary.LittleEndian.PutUint32(dst, freq)
+	return nil
+}
+
+// DecodeBCD decodes packed BCD, two decimal digits per byte with the most
+// significant digits first.
+func DecodeBCD(data []byte) (uint32, error) {
+	var value uint64
+	for _, b := range data {
+		high, low := b>>4, b&0x0F
+		if high > 9 || low > 9 {
+			return 0, fmt.Errorf("invalid BCD byte 0x%02X", b)
+		}
+		value = value*100 + uint64(high)*10 + uint64(low)
+	}
+	if value > math.MaxUint32 {
+		return 0, fmt.Errorf("BCD value %d out of range", value)
+	}
+	return uint32(value), nil
+}
+
+func EncodeBCD(dst []byte, value uint32) error {
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte(value%100/10)<<4 | byte(value%10)
+		value /= 100
+	}
+	if value != 0 {
+		return fmt.Errorf("value does not fit in %d BCD bytes", len(dst))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package codeplug
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestFreqRoundTrip(t *testing.T) {
+	tests := []struct {
+		mhz  float64
+		freq uint32
+		text string
+	}{
+		{0, 0, "0.0"},
+		{0.00001, 1, "0.00001"},
+		{136, 13600000, "136.0"},
+		{146.52, 14652000, "146.52"},
+		{174, 17400000, "174.0"},
+		{400, 40000000, "400.0"},
+		{446.00625, 44600625, "446.00625"},
+		{462.5625, 46256250, "462.5625"},
+		{480, 48000000, "480.0"},
+		{42949.67295, math.MaxUint32, "42949.67295"},
+	}
+
+	for _, tt := range tests {
+		freq, err := MHzToFreq(tt.mhz)
+		if err != nil {
+			t.Errorf("MHzToFreq(%g): %v", tt.mhz, err)
+			continue
+		}
+		if freq != tt.freq {
+			t.Errorf("MHzToFreq(%g) = %d, want %d", tt.mhz, freq, tt.freq)
+		}
+		if got := FreqToMHz(int64(freq)); got != tt.mhz {
+			t.Errorf("FreqToMHz(%d) = %g, want %g", freq, got, tt.mhz)
+		}
+		if got := FormatMHz(int64(freq), true); got != tt.text {
+			t.Errorf("FormatMHz(%d, true) = %q, want %q", freq, got, tt.text)
+		}
+
+		buf := make([]byte, 4)
+		if err := FreqEncodingLittleEndian.Encode(buf, freq); err != nil {
+			t.Errorf("Encode(%d): %v", freq, err)
+			continue
+		}
+		if got, err := FreqEncodingLittleEndian.Decode(buf); err != nil || got != freq {
+			t.Errorf("Decode(Encode(%d)) = %d, %v", freq, got, err)
+		}
+	}
+}
+
+func TestMHzToFreqOutOfRange(t *testing.T) {
+	for _, mhz := range []float64{-0.00001, 42949.67296} {
+		if freq, err := MHzToFreq(mhz); err == nil {
+			t.Errorf("MHzToFreq(%g) = %d, want an error", mhz, freq)
+		}
+	}
+}
+
+func TestBCDKnownFrequencies(t *testing.T) {
+	tests := []struct {
+		mhz  float64
+		bcd  []byte
+		text string
+	}{
+		{146.52, []byte{0x14, 0x65, 0x20, 0x00}, "146.52"},
+		{445, []byte{0x44, 0x50, 0x00, 0x00}, "445.0"},
+		{446.00625, []byte{0x44, 0x60, 0x06, 0x25}, "446.00625"},
+		{462.5625, []byte{0x46, 0x25, 0x62, 0x50}, "462.5625"},
+	}
+
+	for _, tt := range tests {
+		freq, err := FreqEncodingBCD.Decode(tt.bcd)
+		if err != nil {
+			t.Errorf("Decode(% x): %v", tt.bcd, err)
+			continue
+		}
+		if got := FreqToMHz(int64(freq)); got != tt.mhz {
+			t.Errorf("Decode(% x) = %g MHz, want %g", tt.bcd, got, tt.mhz)
+		}
+		if got := FormatMHz(int64(freq), true); got != tt.text {
+			t.Errorf("FormatMHz(%d, true) = %q, want %q", freq, got, tt.text)
+		}
+
+		want, err := MHzToFreq(tt.mhz)
+		if err != nil {
+			t.Fatalf("MHzToFreq(%g): %v", tt.mhz, err)
+		}
+		buf := make([]byte, 4)
+		if err := FreqEncodingBCD.Encode(buf, want); err != nil || !bytes.Equal(buf, tt.bcd) {
+			t.Errorf("Encode(%g MHz) = % x, %v, want % x", tt.mhz, buf, err, tt.bcd)
+		}
+	}
+}
+
+func TestBCDRejectsInvalid(t *testing.T) {
+	if freq, err := DecodeBCD([]byte{0x14, 0x6A, 0x20, 0x00}); err == nil {
+		t.Errorf("DecodeBCD accepted a non-decimal nibble: %d", freq)
+	}
+	if err := EncodeBCD(make([]byte, 4), 100000000); err == nil {
+		t.Error("EncodeBCD fit 9 digits in 4 bytes")
+	}
+	if _, err := FreqEncodingBCD.Decode([]byte{0x14, 0x65}); err == nil {
+		t.Error("Decode accepted a 2 byte field")
+	}
+}
@@ -0,0 +1,54 @@
+package codeplug
+
+// FileLayout describes where each decoded section lives in the file. Ends
+// are exclusive. The header runs up to where the channel records start.
+type FileLayout struct {
+	HeaderSize    int64 `json:"header_size"`
+	ChannelsStart int64 `json:"channels_start"`
+	ChannelsEnd   int64 `json:"channels_end"`
+	RadioIDsStart int64 `json:"radio_ids_start"`
+	RadioIDsEnd   int64 `json:"radio_ids_end"`
+	FileSize      int64 `json:"file_size"`
+}
+
+func (cp *Codeplug) Layout() (*FileLayout, error) {
+	size, err := cp.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	channelsStart, err := cp.channelsStartOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	channelsEnd, err := cp.channelsEndOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	radioIDsStart, err := cp.calculateRadioIDOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := cp.GetRadioIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	radioIDsEnd := radioIDsStart
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		radioIDsEnd = last.Position + int64(last.Length)
+	}
+
+	return &FileLayout{
+		HeaderSize:    channelsStart,
+		ChannelsStart: channelsStart,
+		ChannelsEnd:   channelsEnd,
+		RadioIDsStart: radioIDsStart,
+		RadioIDsEnd:   radioIDsEnd,
+		FileSize:      size,
+	}, nil
+}
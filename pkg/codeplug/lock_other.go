@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package codeplug
+
+import "os"
+
+func lockFile(file *os.File, exclusive bool) error {
+	return nil
+}
+
+func isLockError(err error) bool {
+	return false
+}
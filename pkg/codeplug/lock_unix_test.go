@@ -0,0 +1,45 @@
+//go:build unix
+
+package codeplug
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenReadOnlySharesLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codeplug.rdt")
+	if err := os.WriteFile(path, buildCodeplug(testChannels, testRadioIDs), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	second, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("second OpenReadOnly: %v", err)
+	}
+
+	if _, err := Open(path); !errors.Is(err, ErrFileLocked) {
+		t.Errorf("Open while readers hold the file = %v, want ErrFileLocked", err)
+	}
+	if err := first.SetChannelName(0, "Renamed"); err == nil {
+		t.Error("SetChannelName succeeded on a read-only codeplug")
+	}
+	checkCodeplug(t, second, testChannels, testRadioIDs)
+	first.Close()
+	second.Close()
+
+	writer, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open after readers closed: %v", err)
+	}
+	defer writer.Close()
+	if _, err := OpenReadOnly(path); !errors.Is(err, ErrFileLocked) {
+		t.Errorf("OpenReadOnly while a writer holds the file = %v, want ErrFileLocked", err)
+	}
+}
@@ -0,0 +1,161 @@
+package codeplug
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The channel count is stored in a single byte, which caps every model
+// regardless of what the radio itself supports.
+const maxChannelCount = 0xFF
+
+type FreqRange struct {
+	Low  uint32
+	High uint32
+}
+
+type Model struct {
+	Name                 string
+	ID                   string
+	MaxChannels          int
+	MaxChannelNameLength int
+	MaxRadioIDNameLength int
+	FreqRanges           []FreqRange
+	ChannelsOffset       int64
+	FreqEncoding         FreqEncoding
+}
+
+// On the D878 family the channel records follow the channel count byte
+// directly.
+const d878ChannelsOffset = totalChannelsAddress + 1
+
+var d878FreqRanges = []FreqRange{
+	{Low: mhz(136), High: mhz(174)},
+	{Low: mhz(400), High: mhz(480)},
+}
+
+var Models = []Model{
+	{Name: "AT-D878UVII", ID: "D878UV2", MaxChannels: 4000, MaxChannelNameLength: 16, MaxRadioIDNameLength: 16, FreqRanges: d878FreqRanges, ChannelsOffset: d878ChannelsOffset},
+	{Name: "AT-D878UV", ID: "D878UV", MaxChannels: 4000, MaxChannelNameLength: 16, MaxRadioIDNameLength: 16, FreqRanges: d878FreqRanges, ChannelsOffset: d878ChannelsOffset},
+}
+
+var defaultModel = Model{Name: "Unknown", MaxChannels: 4000, MaxChannelNameLength: 16, MaxRadioIDNameLength: 16, ChannelsOffset: d878ChannelsOffset}
+
+func LookupModel(id string) (Model, bool) {
+	id = strings.TrimRight(id, "\x00 ")
+	for _, m := range Models {
+		if strings.HasPrefix(id, m.ID) {
+			return m, true
+		}
+	}
+	return defaultModel, false
+}
+
+// ModelVariant returns what follows the known model ID in a model string,
+// such as a region or sub-variant suffix. It is empty for unknown models.
+func ModelVariant(id string) string {
+	id = strings.TrimRight(id, "\x00 ")
+	model, ok := LookupModel(id)
+	if !ok {
+		return ""
+	}
+	return strings.Trim(strings.TrimPrefix(id, model.ID), "\x00 ")
+}
+
+func (m Model) ChannelLimit() int {
+	if m.MaxChannels > maxChannelCount {
+		return maxChannelCount
+	}
+	return m.MaxChannels
+}
+
+// CheckFrequency reports whether freq falls inside one of the model's
+// supported ranges. Models without known ranges accept any frequency.
+func (m Model) CheckFrequency(freq uint32) error {
+	if len(m.FreqRanges) == 0 {
+		return nil
+	}
+	for _, r := range m.FreqRanges {
+		if freq >= r.Low && freq <= r.High {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s MHz is outside the frequency ranges supported by %s", FormatMHz(int64(freq), false), m.Name)
+}
+
+// ChannelRecordSize returns the smallest and largest plausible channel
+// record for the model: an empty name and a name of the maximum length.
+func (m Model) ChannelRecordSize() (int, int) {
+	fixed := channelHeaderSize + 1 + channelTrailingSize
+	return fixed, fixed + m.MaxChannelNameLength
+}
+
+func (m Model) CheckChannelName(name string) error {
+	return checkNameLength("channel", name, m.Name, m.MaxChannelNameLength)
+}
+
+// ChannelNameProblems lists why name would not display correctly on the
+// radio: it is wider than the display or contains characters outside
+// printable ASCII. It is empty for a valid name.
+func (m Model) ChannelNameProblems(name string) []string {
+	var problems []string
+	if len(name) > m.MaxChannelNameLength {
+		problems = append(problems, fmt.Sprintf("too long (%d characters, %s shows %d)", len(name), m.Name, m.MaxChannelNameLength))
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < 0x20 || name[i] >= 0x7F {
+			problems = append(problems, fmt.Sprintf("bad character 0x%02X at position %d", name[i], i))
+		}
+	}
+	return problems
+}
+
+func (m Model) CheckRadioIDName(name string) error {
+	return checkNameLength("radio ID", name, m.Name, m.MaxRadioIDNameLength)
+}
+
+func checkNameLength(kind, name, model string, limit int) error {
+	if len(name) > limit {
+		return fmt.Errorf("%s name %q is %d characters long; %s allows at most %d", kind, name, len(name), model, limit)
+	}
+	return nil
+}
+
+func (cp *Codeplug) readModel() (string, error) {
+	model := make([]byte, modelSize)
+	if err := cp.readAt(model, modelOffset); err != nil {
+		return "", fmt.Errorf("failed to read model: %w", err)
+	}
+	return string(model), nil
+}
+
+// RawModel returns the model bytes exactly as stored in the file.
+func (cp *Codeplug) RawModel() ([]byte, error) {
+	id, err := cp.readModel()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(id), nil
+}
+
+func (cp *Codeplug) Model() (Model, error) {
+	if cp.model != nil {
+		return *cp.model, nil
+	}
+
+	id, err := cp.readModel()
+	if err != nil {
+		return Model{}, err
+	}
+	model, _ := LookupModel(id)
+	cp.model = &model
+	return model, nil
+}
+
+// invalidateModel drops the cached model if length bytes changed at offset
+// overlap the model ID.
+func (cp *Codeplug) invalidateModel(offset, length int64) {
+	if offset < modelOffset+modelSize && offset+length > modelOffset {
+		cp.model = nil
+	}
+}
@@ -0,0 +1,41 @@
+package codeplug
+
+import "testing"
+
+// modelReadCounter counts reads that start at the model ID.
+type modelReadCounter struct {
+	memStorage
+	reads int
+}
+
+func (m *modelReadCounter) ReadAt(p []byte, offset int64) (int, error) {
+	if offset == modelOffset {
+		m.reads++
+	}
+	return m.memStorage.ReadAt(p, offset)
+}
+
+func TestModelIsCached(t *testing.T) {
+	storage := &modelReadCounter{memStorage: memStorage{data: buildCodeplug(testChannels, testRadioIDs)}}
+	cp := New(storage)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cp.GetChannels(); err != nil {
+			t.Fatalf("GetChannels: %v", err)
+		}
+	}
+	if storage.reads != 1 {
+		t.Errorf("model read %d times, want once", storage.reads)
+	}
+
+	if _, err := cp.writeAt([]byte("D878UV\x00\x00\x00\x00"), modelOffset); err != nil {
+		t.Fatalf("writeAt: %v", err)
+	}
+	model, err := cp.Model()
+	if err != nil {
+		t.Fatalf("Model: %v", err)
+	}
+	if model.ID != "D878UV" {
+		t.Errorf("model after rewriting the model ID = %s, want D878UV", model.ID)
+	}
+}
@@ -0,0 +1,82 @@
+package codeplug
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// openGD77Columns is the header of the Channels.csv file read and written
+// by the OpenGD77 CPS.
+var openGD77Columns = []string{
+	"Channel Number", "Channel Name", "Channel Type", "Rx Frequency", "Tx Frequency",
+	"Bandwidth (kHz)", "Colour Code", "Timeslot", "Contact", "TG List", "DMR ID",
+	"TS1_TA_Tx", "TS2_TA_Tx ID", "RX Tone", "TX Tone", "Squelch", "Power",
+	"Rx Only", "Zone Skip", "All Skip", "TOT", "VOX", "No Beep", "No Eco",
+	"APRS", "Latitude", "Longitude",
+}
+
+// ExportOpenGD77CSV writes channels in the OpenGD77 CPS channel CSV format.
+// OpenGD77 has no mixed mode, so A+D channels are written as analogue and
+// D+A channels as digital. Fields OpenGD77 stores elsewhere or not at all,
+// such as power, contacts, scan lists and encryption, are written as the
+// CPS defaults.
+func ExportOpenGD77CSV(w io.Writer, channels []*Channel) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(openGD77Columns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for i, channel := range channels {
+		if err := writer.Write(openGD77Row(i+1, channel)); err != nil {
+			return fmt.Errorf("failed to write channel %d: %w", channel.Index, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func openGD77Row(number int, c *Channel) []string {
+	digital := c.ChannelType == ChannelTypeDigital || c.ChannelType == ChannelTypeDigitalAnalog
+
+	channelType, bandwidth, colourCode, timeslot := "Analogue", "25", "", ""
+	rxTone := openGD77Tone(c.CtcssDcsDecodeOption, c.CtcssDcsDecode)
+	txTone := openGD77Tone(c.CtcssDcsEncodeOption, c.CtcssDcsEncode)
+	squelch := "Disabled"
+	if c.Bandwidth == Bandwidth12_5 {
+		bandwidth = "12.5"
+	}
+	if digital {
+		channelType, bandwidth, colourCode, timeslot = "Digital", "", strconv.Itoa(int(c.RxColorCode)), strconv.Itoa(int(c.Slot)+1)
+		rxTone, txTone, squelch = "", "", ""
+	}
+
+	return []string{
+		strconv.Itoa(number), c.Name, channelType,
+		openGD77Freq(int64(c.RxFreq)), openGD77Freq(int64(c.TxFreq)),
+		bandwidth, colourCode, timeslot, "None", "None", "None",
+		"Off", "Off", rxTone, txTone, squelch, "Master",
+		"No", "No", "No", "0", "Off", "No", "No",
+		"No", "0", "0",
+	}
+}
+
+// openGD77Freq formats a frequency in MHz with the five decimal places the
+// OpenGD77 CPS writes.
+func openGD77Freq(freq int64) string {
+	return fmt.Sprintf("%d.%05d", freq/FreqScale, freq%FreqScale)
+}
+
+// openGD77Tone writes a tone as OpenGD77 does: "None", a CTCSS frequency
+// such as "100.0", or a DCS code such as "D023N". A tone this package
+// cannot decode is written as "None" rather than a value the CPS rejects.
+func openGD77Tone(option, index byte) string {
+	tone := formatTone(option, index)
+	if option == ToneOff || strings.HasPrefix(tone, "unknown") {
+		return "None"
+	}
+	return strings.TrimSuffix(tone, " Hz")
+}
@@ -0,0 +1,37 @@
+package codeplug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExportOpenGD77CSV(t *testing.T) {
+	cp := newTestCodeplug(t)
+	if err := cp.SetChannelTones(1, "D023I", "100.0"); err != nil {
+		t.Fatalf("SetChannelTones: %v", err)
+	}
+	channels, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := ExportOpenGD77CSV(&out, channels); err != nil {
+		t.Fatalf("ExportOpenGD77CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(channels)+1 {
+		t.Fatalf("got %d lines, want a header and %d channels", len(lines), len(channels))
+	}
+
+	want := []string{
+		"2,W1AW Rptr,Analogue,146.94000,146.34000,25,,,None,None,None,Off,Off,D023I,100.0,Disabled,Master,No,No,No,0,Off,No,No,No,0,0",
+		"3,DMR Local,Digital,445.00000,445.00000,,0,1,None,None,None,Off,Off,,,,Master,No,No,No,0,Off,No,No,No,0,0",
+	}
+	for i, line := range want {
+		if lines[i+2] != line {
+			t.Errorf("line %d = %q, want %q", i+2, lines[i+2], line)
+		}
+	}
+}
@@ -2,45 +2,96 @@ package codeplug
 
 import (
 	"fmt"
+	"sort"
 )
 
 type RadioIDEntry struct {
-	Index    int
-	ID       int
-	Name     string
-	Position int64
-	Length   int
+	Index    int    `json:"index"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Position int64  `json:"-"`
+	Length   int    `json:"-"`
 }
 
+const radioIDSectionGap = 2
+
+// calculateRadioIDOffset locates the radio ID section after the channel
+// list. If the channel count is wrong the walk ends in the wrong place, so
+// when no plausible entry is found there every channel record boundary is
+// tried instead. The result is cached, since an empty section would
+// otherwise be scanned for on every lookup.
 func (cp *Codeplug) calculateRadioIDOffset() (int64, error) {
-	channelCountBuf := make([]byte, 1)
-	if _, err := cp.file.ReadAt(channelCountBuf, totalChannelsAddress); err != nil {
-		return 0, fmt.Errorf("failed to read total channels: %w", err)
+	if cp.radioIDOffset != 0 {
+		return cp.radioIDOffset, nil
 	}
 
-	totalChannels := int(channelCountBuf[0])
-
-	channelsStartOffset := int64(totalChannelsAddress + 1)
-
-	currentOffset := channelsStartOffset
+	channelsEndOffset, err := cp.channelsEndOffset()
+	if err == nil && cp.isRadioIDEntryAt(channelsEndOffset+radioIDSectionGap) {
+		cp.radioIDOffset = channelsEndOffset + radioIDSectionGap
+		return cp.radioIDOffset, nil
+	}
 
-	for i := 0; i < totalChannels; i++ {
-		channel, err := cp.readChannelMetadata(currentOffset)
+	found, channels, ok := cp.scanForRadioIDSection()
+	if !ok {
 		if err != nil {
-			return 0, fmt.Errorf("failed to read channel %d: %w", i+1, err)
+			return 0, err
 		}
+		cp.radioIDOffset = channelsEndOffset + radioIDSectionGap
+		return cp.radioIDOffset, nil
+	}
+
+	count, _ := cp.channelCount()
+	cp.logf("radio ID section not found after %d channels; found it after %d channels at offset %d", count, channels, found)
+	cp.radioIDOffset = found
+	return found, nil
+}
+
+// invalidateRadioIDOffset drops the cached radio ID section offset if a
+// change at offset could move it or change what the scan finds: anything up
+// to and including the first entry. Changes to later entries keep it.
+func (cp *Codeplug) invalidateRadioIDOffset(offset int64) {
+	if offset <= cp.radioIDOffset {
+		cp.radioIDOffset = 0
+	}
+}
+
+func (cp *Codeplug) isRadioIDEntryAt(offset int64) bool {
+	entry, err := cp.readRadioIDEntry(offset, -1)
+	return err == nil && entry.Index < maxRadioIDs && entry.ID != 0 && isPrintableName(entry.Name)
+}
 
-		currentOffset += int64(channel.TotalLength)
+func (cp *Codeplug) scanForRadioIDSection() (int64, int, bool) {
+	model, err := cp.Model()
+	if err != nil {
+		return 0, 0, false
 	}
 
-	radioIDOffset := currentOffset + 2
+	offset := model.ChannelsOffset
+	for i := 0; i <= maxChannelCount; i++ {
+		if cp.isRadioIDEntryAt(offset + radioIDSectionGap) {
+			return offset + radioIDSectionGap, i, true
+		}
 
-	return radioIDOffset, nil
+		channel, err := cp.readChannelMetadata(offset)
+		if err != nil {
+			return 0, 0, false
+		}
+		offset += int64(channel.TotalLength)
+	}
+	return 0, 0, false
+}
+
+func (cp *Codeplug) channelsEndOffset() (int64, error) {
+	offsets, err := cp.loadChannelOffsets()
+	if err != nil {
+		return 0, err
+	}
+	return offsets[len(offsets)-1], nil
 }
 
 func (cp *Codeplug) readRadioIDEntry(offset int64, previousIndex int) (*RadioIDEntry, error) {
 	idHeader := make([]byte, 4)
-	if _, err := cp.file.ReadAt(idHeader, offset); err != nil {
+	if err := cp.readAt(idHeader, offset); err != nil {
 		return nil, fmt.Errorf("failed to read radio ID header at offset %d: %w", offset, err)
 	}
 
@@ -53,9 +104,11 @@ func (cp *Codeplug) readRadioIDEntry(offset int64, previousIndex int) (*RadioIDE
 	id := int(uint32(idHeader[1]) | uint32(idHeader[2])<<8 | uint32(idHeader[3])<<16)
 
 	buf := make([]byte, 256)
-	if _, err := cp.file.ReadAt(buf, offset+4); err != nil {
+	n, err := cp.readUpTo(buf, offset+4)
+	if err != nil {
 		return nil, fmt.Errorf("failed to read radio ID name at offset %d: %w", offset+4, err)
 	}
+	buf = buf[:n]
 
 	nameLength := 0
 	for j := 0; j < len(buf); j++ {
@@ -65,6 +118,10 @@ func (cp *Codeplug) readRadioIDEntry(offset int64, previousIndex int) (*RadioIDE
 		}
 	}
 
+	if nameLength == 0 {
+		return nil, &ParseError{Offset: offset + 4, Reason: "invalid radio ID name: no null terminator found"}
+	}
+
 	name := string(buf[:nameLength-1])
 
 	return &RadioIDEntry{
@@ -76,7 +133,15 @@ func (cp *Codeplug) readRadioIDEntry(offset int64, previousIndex int) (*RadioIDE
 	}, nil
 }
 
-func (cp *Codeplug) writeRadioIDEntry(entry *RadioIDEntry) error {
+func (cp *Codeplug) encodeRadioIDEntry(entry *RadioIDEntry) ([]byte, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+	if err := model.CheckRadioIDName(entry.Name); err != nil {
+		return nil, err
+	}
+
 	totalLength := 4 + len(entry.Name) + 1
 	buf := make([]byte, totalLength)
 
@@ -88,13 +153,42 @@ func (cp *Codeplug) writeRadioIDEntry(entry *RadioIDEntry) error {
 
 	copy(buf[4:], entry.Name)
 
-	if _, err := cp.file.WriteAt(buf, entry.Position); err != nil {
+	return buf, nil
+}
+
+// writeRadioIDEntry overwrites an existing entry in place. Entries are packed
+// back to back, so a serialized entry that is longer or shorter than the one
+// on disk would corrupt its neighbour and is rejected.
+func (cp *Codeplug) writeRadioIDEntry(entry *RadioIDEntry) error {
+	buf, err := cp.encodeRadioIDEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	if len(buf) != entry.Length {
+		return fmt.Errorf("radio ID entry at offset %d would change length from %d to %d bytes", entry.Position, entry.Length, len(buf))
+	}
+
+	if _, err := cp.writeAt(buf, entry.Position); err != nil {
 		return fmt.Errorf("failed to write radio ID entry: %w", err)
 	}
 
 	return nil
 }
 
+func (cp *Codeplug) insertRadioIDEntry(entry *RadioIDEntry) error {
+	buf, err := cp.encodeRadioIDEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := cp.insertBytes(entry.Position, buf); err != nil {
+		return fmt.Errorf("failed to insert radio ID entry: %w", err)
+	}
+
+	return nil
+}
+
 func (cp *Codeplug) UpdateRadioID(index int, newID int) error {
 	if index < 0 || index >= maxRadioIDs {
 		return fmt.Errorf("invalid radio ID index: %d", index)
@@ -154,7 +248,7 @@ func (cp *Codeplug) UpdateRadioID(index int, newID int) error {
 		Length:   4 + len(fmt.Sprintf("Radio ID %d", index+1)) + 1,
 	}
 
-	return cp.writeRadioIDEntry(newEntry)
+	return cp.insertRadioIDEntry(newEntry)
 }
 
 func (cp *Codeplug) GetRadioIDs() ([]*RadioIDEntry, error) {
@@ -218,3 +312,94 @@ func (cp *Codeplug) GetRadioIDByIndex(index int) (*RadioIDEntry, error) {
 
 	return nil, fmt.Errorf("radio ID with index %d not found", index)
 }
+
+// OrphanRadioIDChannels returns the digital and mixed-mode channels whose
+// radio ID index has no matching radio ID entry. Analog channels never
+// transmit a radio ID, so whatever their index holds is ignored.
+func (cp *Codeplug) OrphanRadioIDChannels() ([]*Channel, error) {
+	entries, err := cp.GetRadioIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		known[entry.Index] = true
+	}
+
+	var orphans []*Channel
+	err = cp.ForEachChannel(func(channel *Channel) error {
+		if channel.ChannelType != ChannelTypeAnalog && !known[int(channel.RadioId)] {
+			orphans = append(orphans, channel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orphans, nil
+}
+
+// scanRadioIDEntries reads radio ID entries without relying on ascending
+// indices to find the end of the section, so out-of-order entries are still
+// found. It stops at the first entry that cannot be part of the section.
+func (cp *Codeplug) scanRadioIDEntries(offset int64) ([]*RadioIDEntry, error) {
+	currentOffset := offset
+	seen := make(map[int]bool)
+	entries := make([]*RadioIDEntry, 0, maxRadioIDs)
+
+	for i := 0; i < maxRadioIDs; i++ {
+		entry, err := cp.readRadioIDEntry(currentOffset, -1)
+		if err != nil || entry.Index >= maxRadioIDs || seen[entry.Index] || !isPrintableName(entry.Name) {
+			break
+		}
+
+		seen[entry.Index] = true
+		entries = append(entries, entry)
+		currentOffset += int64(entry.Length)
+	}
+
+	return entries, nil
+}
+
+func isPrintableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < 0x20 || name[i] >= 0x7F {
+			return false
+		}
+	}
+	return true
+}
+
+func (cp *Codeplug) RepairRadioIDs() (bool, error) {
+	radioIDOffset, err := cp.calculateRadioIDOffset()
+	if err != nil {
+		return false, fmt.Errorf("failed to calculate radio ID offset: %w", err)
+	}
+
+	entries, err := cp.scanRadioIDEntries(radioIDOffset)
+	if err != nil {
+		return false, err
+	}
+
+	byIndex := func(i, j int) bool { return entries[i].Index < entries[j].Index }
+	if sort.SliceIsSorted(entries, byIndex) {
+		return false, nil
+	}
+	sort.Slice(entries, byIndex)
+
+	position := radioIDOffset
+	for _, entry := range entries {
+		entry.Position = position
+		if err := cp.writeRadioIDEntry(entry); err != nil {
+			return false, err
+		}
+		position += int64(entry.Length)
+	}
+
+	return true, nil
+}
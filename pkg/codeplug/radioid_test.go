@@ -0,0 +1,153 @@
+package codeplug
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzReadRadioIDEntry(f *testing.F) {
+	f.Add(radioIDRecord(testRadioIDs[0]))
+	f.Add(radioIDRecord(RadioIDEntry{Index: 9, ID: 0xFFFFFF, Name: ""}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cp := New(&memStorage{data: data})
+
+		entry, err := cp.readRadioIDEntry(0, -1)
+		if err != nil {
+			return
+		}
+
+		if entry.Length != 4+len(entry.Name)+1 || entry.Length > len(data) {
+			t.Fatalf("entry length %d does not fit name %q in %d bytes", entry.Length, entry.Name, len(data))
+		}
+		if bytes.IndexByte([]byte(entry.Name), 0) >= 0 {
+			t.Fatalf("name %q contains its terminator", entry.Name)
+		}
+		if entry.ID > 0xFFFFFF {
+			t.Fatalf("ID %d does not fit in 3 bytes", entry.ID)
+		}
+	})
+}
+
+func TestUpdateRadioIDInsertsEntry(t *testing.T) {
+	cp := newTestCodeplug(t)
+	if err := cp.UpdateRadioID(5, 3100001); err != nil {
+		t.Fatalf("UpdateRadioID: %v", err)
+	}
+
+	want := append(append([]RadioIDEntry(nil), testRadioIDs...), RadioIDEntry{Index: 5, ID: 3100001, Name: "Radio ID 6"})
+	checkCodeplug(t, cp, testChannels, want)
+
+	if err := cp.UpdateRadioID(0, 3199999); err != nil {
+		t.Fatalf("UpdateRadioID in place: %v", err)
+	}
+	want[0].ID = 3199999
+	checkCodeplug(t, cp, testChannels, want)
+}
+
+func TestWriteRadioIDEntryRejectsLengthChange(t *testing.T) {
+	cp := newTestCodeplug(t)
+	entries, err := cp.GetRadioIDs()
+	if err != nil {
+		t.Fatalf("GetRadioIDs: %v", err)
+	}
+
+	entry := *entries[0]
+	entry.Name = "A longer name"
+	if err := cp.writeRadioIDEntry(&entry); err == nil {
+		t.Fatal("writeRadioIDEntry accepted a name that changes the entry length")
+	}
+	checkCodeplug(t, cp, testChannels, testRadioIDs)
+}
+
+func TestRadioIDSectionFoundWithWrongChannelCount(t *testing.T) {
+	data := buildCodeplug(testChannels, testRadioIDs)
+	want := int64(d878ChannelsOffset + radioIDSectionGap)
+	for _, c := range testChannels {
+		want += int64(len(channelRecord(c)))
+	}
+
+	for _, count := range []int{len(testChannels) - 1, len(testChannels) + 1} {
+		cp := NewFromBytes(data)
+		if err := cp.setChannelCount(count); err != nil {
+			t.Fatalf("setChannelCount: %v", err)
+		}
+
+		offset, err := cp.calculateRadioIDOffset()
+		if err != nil {
+			t.Fatalf("count %d: calculateRadioIDOffset: %v", count, err)
+		}
+		if offset != want {
+			t.Errorf("count %d: radio IDs found at offset %d, want %d", count, offset, want)
+		}
+
+		entries, err := cp.GetRadioIDs()
+		if err != nil {
+			t.Fatalf("count %d: GetRadioIDs: %v", count, err)
+		}
+		if len(entries) != len(testRadioIDs) || entries[0].ID != testRadioIDs[0].ID {
+			t.Errorf("count %d: got %d radio IDs, want %d", count, len(entries), len(testRadioIDs))
+		}
+	}
+}
+
+func TestOrphanRadioIDChannelsIgnoresAnalog(t *testing.T) {
+	cp := newTestCodeplug(t)
+	channels, err := cp.GetChannels()
+	if err != nil {
+		t.Fatalf("GetChannels: %v", err)
+	}
+	// Point an analog and a digital channel at radio ID 9, which does not
+	// exist. The radio ID index is header byte 31.
+	for _, index := range []int{0, 2} {
+		if err := cp.writeHeader(channels[index], 31, []byte{9}); err != nil {
+			t.Fatalf("writeHeader: %v", err)
+		}
+	}
+
+	orphans, err := cp.OrphanRadioIDChannels()
+	if err != nil {
+		t.Fatalf("OrphanRadioIDChannels: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].Index != 2 {
+		t.Errorf("got %d orphans, want only digital channel 2", len(orphans))
+	}
+}
+
+// scanReadCounter counts reads at the first offset the radio ID scan tries.
+type scanReadCounter struct {
+	memStorage
+	reads int
+}
+
+func (s *scanReadCounter) ReadAt(p []byte, offset int64) (int, error) {
+	if offset == d878ChannelsOffset+radioIDSectionGap {
+		s.reads++
+	}
+	return s.memStorage.ReadAt(p, offset)
+}
+
+func TestEmptyRadioIDSectionScannedOnce(t *testing.T) {
+	storage := &scanReadCounter{memStorage: memStorage{data: buildCodeplug(testChannels, nil)}}
+	cp := New(storage)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cp.GetRadioIDs(); err != nil {
+			t.Fatalf("GetRadioIDs: %v", err)
+		}
+	}
+	if storage.reads != 1 {
+		t.Errorf("radio ID section scanned %d times, want once", storage.reads)
+	}
+
+	if err := cp.UpdateRadioID(0, 3100001); err != nil {
+		t.Fatalf("UpdateRadioID: %v", err)
+	}
+	entry, err := cp.GetRadioIDByIndex(0)
+	if err != nil {
+		t.Fatalf("GetRadioIDByIndex: %v", err)
+	}
+	if entry.ID != 3100001 {
+		t.Errorf("radio ID 0 = %d after writing it, want 3100001", entry.ID)
+	}
+}
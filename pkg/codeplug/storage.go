@@ -0,0 +1,150 @@
+package codeplug
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReaderWriterAt is the storage a Codeplug reads and writes. Open uses the
+// opened file; New accepts any implementation, such as an in-memory buffer.
+// Operations that grow or shrink the codeplug also need the storage to
+// provide Size() int64 and Truncate(int64) error, which *os.File does via
+// Open.
+type ReaderWriterAt interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+type sizer interface {
+	Size() int64
+}
+
+type truncater interface {
+	Truncate(size int64) error
+}
+
+// New returns a Codeplug backed by rw. Nothing is locked and Backup is not
+// available since there is no path to write the backup next to.
+func New(rw ReaderWriterAt) *Codeplug {
+	return &Codeplug{rw: rw}
+}
+
+// NewFromBytes returns a Codeplug that works on an in-memory copy of data,
+// for building up changes that are written back in one go with Restore.
+func NewFromBytes(data []byte) *Codeplug {
+	return New(&memStorage{data: append([]byte(nil), data...)})
+}
+
+// memStorage is a ReaderWriterAt over a byte slice that grows on writes
+// past the end.
+type memStorage struct {
+	data []byte
+}
+
+func (m *memStorage) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+	if offset >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[offset:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memStorage) WriteAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+	if end := offset + int64(len(p)); end > int64(len(m.data)) {
+		m.data = append(m.data, make([]byte, end-int64(len(m.data)))...)
+	}
+	return copy(m.data[offset:], p), nil
+}
+
+func (m *memStorage) Size() int64 {
+	return int64(len(m.data))
+}
+
+func (m *memStorage) Truncate(size int64) error {
+	if size < 0 || size > int64(len(m.data)) {
+		return fmt.Errorf("cannot truncate %d bytes to %d", len(m.data), size)
+	}
+	m.data = m.data[:size]
+	return nil
+}
+
+// readAt fills buf from offset. io.ReaderAt implementations may report
+// io.EOF alongside a complete read at the end of the data, which is not an
+// error here.
+func (cp *Codeplug) readAt(buf []byte, offset int64) error {
+	if offset < 0 {
+		return fmt.Errorf("negative offset %d", offset)
+	}
+	n, err := cp.rw.ReadAt(buf, offset)
+	if n == len(buf) && errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func (cp *Codeplug) truncate(size int64) error {
+	cp.invalidateChannelOffsets(size)
+	cp.invalidateModel(size, modelOffset+modelSize)
+
+	var removed []byte
+	if cp.onMutation != nil {
+		current, err := cp.Size()
+		if err != nil {
+			return err
+		}
+		if current > size {
+			removed = make([]byte, current-size)
+			if err := cp.readAt(removed, size); err != nil {
+				return fmt.Errorf("failed to read at offset %d: %w", size, err)
+			}
+		}
+	}
+
+	var err error
+	if cp.file != nil {
+		err = cp.file.Truncate(size)
+	} else if t, ok := cp.rw.(truncater); ok {
+		err = t.Truncate(size)
+	} else {
+		return fmt.Errorf("storage cannot be resized")
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		cp.onMutation(size, removed, nil)
+	}
+	return nil
+}
+
+func (cp *Codeplug) sync() error {
+	if cp.file != nil {
+		return cp.file.Sync()
+	}
+	return nil
+}
+
+// readUpTo reads up to len(buf) bytes from offset and returns how many were
+// available, for variable-length fields that may end close to the end of
+// the data.
+func (cp *Codeplug) readUpTo(buf []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+	n, err := cp.rw.ReadAt(buf, offset)
+	if n > 0 && errors.Is(err, io.EOF) {
+		return n, nil
+	}
+	return n, err
+}
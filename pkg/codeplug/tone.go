@@ -0,0 +1,175 @@
+package codeplug
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tone option bytes, stored next to each tone index.
+const (
+	ToneOff byte = iota
+	ToneCTCSS
+	ToneDCSNormal
+	ToneDCSInverted
+)
+
+// CTCSSTones lists the CTCSS frequencies in tenths of a hertz, in the
+// order the tone index refers to them.
+var CTCSSTones = []int{
+	625, 670, 693, 719, 744, 770, 797, 825, 854, 885,
+	915, 948, 974, 1000, 1035, 1072, 1109, 1148, 1188, 1230,
+	1273, 1318, 1365, 1413, 1462, 1514, 1567, 1598, 1622, 1655,
+	1679, 1713, 1738, 1773, 1799, 1835, 1862, 1899, 1928, 1966,
+	1995, 2035, 2065, 2107, 2181, 2257, 2291, 2336, 2418, 2503,
+	2541,
+}
+
+// DCSCodes lists the standard DCS codes, written in octal, in the order the
+// tone index refers to them.
+var DCSCodes = []int{
+	0o023, 0o025, 0o026, 0o031, 0o032, 0o036, 0o043, 0o047, 0o051, 0o053,
+	0o054, 0o065, 0o071, 0o072, 0o073, 0o074, 0o114, 0o115, 0o116, 0o122,
+	0o125, 0o131, 0o132, 0o134, 0o143, 0o145, 0o152, 0o155, 0o156, 0o162,
+	0o165, 0o172, 0o174, 0o205, 0o212, 0o223, 0o225, 0o226, 0o243, 0o244,
+	0o245, 0o246, 0o251, 0o252, 0o255, 0o261, 0o263, 0o265, 0o266, 0o271,
+	0o274, 0o306, 0o311, 0o315, 0o325, 0o331, 0o332, 0o343, 0o346, 0o351,
+	0o356, 0o364, 0o365, 0o371, 0o411, 0o412, 0o413, 0o423, 0o431, 0o432,
+	0o445, 0o446, 0o452, 0o454, 0o455, 0o462, 0o464, 0o465, 0o466, 0o503,
+	0o506, 0o516, 0o523, 0o526, 0o532, 0o546, 0o565, 0o606, 0o612, 0o624,
+	0o627, 0o631, 0o632, 0o654, 0o662, 0o664, 0o703, 0o712, 0o723, 0o731,
+	0o732, 0o734, 0o743, 0o754,
+}
+
+// DecodeRxTone describes the tone the channel requires to open squelch,
+// such as "67.0 Hz", "D023N" or "Off".
+func (c *Channel) DecodeRxTone() string {
+	return formatTone(c.CtcssDcsDecodeOption, c.CtcssDcsDecode)
+}
+
+// DecodeTxTone describes the tone the channel transmits.
+func (c *Channel) DecodeTxTone() string {
+	return formatTone(c.CtcssDcsEncodeOption, c.CtcssDcsEncode)
+}
+
+// formatTone decodes an option and index pair. An option of ToneOff means
+// no tone whatever the index holds; an index past the end of its table is
+// reported rather than guessed at.
+func formatTone(option, index byte) string {
+	switch option {
+	case ToneOff:
+		return "Off"
+	case ToneCTCSS:
+		if int(index) < len(CTCSSTones) {
+			tone := CTCSSTones[index]
+			return fmt.Sprintf("%d.%d Hz", tone/10, tone%10)
+		}
+	case ToneDCSNormal, ToneDCSInverted:
+		if int(index) < len(DCSCodes) {
+			polarity := "N"
+			if option == ToneDCSInverted {
+				polarity = "I"
+			}
+			return fmt.Sprintf("D%03o%s", DCSCodes[index], polarity)
+		}
+	}
+	return fmt.Sprintf("unknown (option %d, index %d)", option, index)
+}
+
+// ParseTone is the inverse of the decoded tone strings: "off", a CTCSS
+// frequency such as "100.0" or "100.0 Hz", or a DCS code such as "D023N",
+// "D023I" or "D023" (normal). It returns the option and index bytes.
+func ParseTone(s string) (byte, byte, error) {
+	tone := strings.ToUpper(strings.TrimSpace(s))
+	if tone == "OFF" || tone == "NONE" {
+		return ToneOff, 0, nil
+	}
+
+	if strings.HasPrefix(tone, "D") {
+		option := ToneDCSNormal
+		digits := tone[1:]
+		switch {
+		case strings.HasSuffix(digits, "N"):
+			digits = digits[:len(digits)-1]
+		case strings.HasSuffix(digits, "I"):
+			option = ToneDCSInverted
+			digits = digits[:len(digits)-1]
+		}
+		code, err := strconv.ParseUint(digits, 8, 16)
+		if err != nil || len(digits) != 3 {
+			return 0, 0, fmt.Errorf("invalid DCS code %q: use a form like D023N or D023I", s)
+		}
+		for i, c := range DCSCodes {
+			if c == int(code) {
+				return option, byte(i), nil
+			}
+		}
+		return 0, 0, fmt.Errorf("unsupported DCS code %q", s)
+	}
+
+	hz, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(tone, "HZ")), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tone %q: use off, a CTCSS frequency such as 100.0 or a DCS code such as D023N", s)
+	}
+	tenths := int(math.Round(hz * 10))
+	for i, t := range CTCSSTones {
+		if t == tenths {
+			return ToneCTCSS, byte(i), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("unsupported CTCSS tone %q", s)
+}
+
+// SetChannelTones sets the tone the channel needs to open squelch and the
+// tone it transmits. Each is anything ParseTone accepts, or empty to leave
+// it unchanged. Both are validated before either is written.
+func (cp *Codeplug) SetChannelTones(index int, rxTone, txTone string) error {
+	type toneField struct {
+		offset int
+		value  []byte
+	}
+
+	var fields []toneField
+	for _, t := range []struct {
+		offset int
+		tone   string
+	}{{headerRxToneOffset, rxTone}, {headerTxToneOffset, txTone}} {
+		if t.tone == "" {
+			continue
+		}
+		option, toneIndex, err := ParseTone(t.tone)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, toneField{t.offset, []byte{toneIndex, option}})
+	}
+
+	channel, err := cp.GetChannelByIndex(index)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if err := cp.writeHeader(channel, f.offset, f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetChannelRxTone sets the tone the channel needs to open squelch.
+func (cp *Codeplug) SetChannelRxTone(index int, tone string) error {
+	if tone == "" {
+		return fmt.Errorf("tone cannot be empty")
+	}
+	return cp.SetChannelTones(index, tone, "")
+}
+
+// SetChannelTxTone sets the tone the channel transmits.
+func (cp *Codeplug) SetChannelTxTone(index int, tone string) error {
+	if tone == "" {
+		return fmt.Errorf("tone cannot be empty")
+	}
+	return cp.SetChannelTones(index, "", tone)
+}
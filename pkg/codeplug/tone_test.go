@@ -0,0 +1,60 @@
+package codeplug
+
+import "testing"
+
+func TestToneRoundTrip(t *testing.T) {
+	tests := []struct {
+		in, want string
+		option   byte
+	}{
+		{"off", "Off", ToneOff},
+		{"67.0", "67.0 Hz", ToneCTCSS},
+		{"100.0 Hz", "100.0 Hz", ToneCTCSS},
+		{"254.1", "254.1 Hz", ToneCTCSS},
+		{"D023", "D023N", ToneDCSNormal},
+		{"D023N", "D023N", ToneDCSNormal},
+		{"D023I", "D023I", ToneDCSInverted},
+		{"D754I", "D754I", ToneDCSInverted},
+	}
+
+	for _, tt := range tests {
+		option, _, err := ParseTone(tt.in)
+		if err != nil {
+			t.Errorf("ParseTone(%q): %v", tt.in, err)
+			continue
+		}
+		if option != tt.option {
+			t.Errorf("ParseTone(%q) option = %d, want %d", tt.in, option, tt.option)
+		}
+
+		cp := newTestCodeplug(t)
+		if err := cp.SetChannelTones(0, tt.in, tt.in); err != nil {
+			t.Errorf("SetChannelTones(%q): %v", tt.in, err)
+			continue
+		}
+		channel, err := cp.GetChannelByIndex(0)
+		if err != nil {
+			t.Fatalf("GetChannelByIndex: %v", err)
+		}
+		if rx, tx := channel.DecodeRxTone(), channel.DecodeTxTone(); rx != tt.want || tx != tt.want {
+			t.Errorf("tone %q decoded as rx %q, tx %q, want %q", tt.in, rx, tx, tt.want)
+		}
+	}
+}
+
+func TestSetChannelTonesValidatesBoth(t *testing.T) {
+	cp := newTestCodeplug(t)
+	for _, tones := range [][2]string{{"D023N", "D024N"}, {"100.3", "D023I"}} {
+		if err := cp.SetChannelTones(0, tones[0], tones[1]); err == nil {
+			t.Errorf("SetChannelTones(%q, %q) accepted an invalid tone", tones[0], tones[1])
+		}
+	}
+
+	channel, err := cp.GetChannelByIndex(0)
+	if err != nil {
+		t.Fatalf("GetChannelByIndex: %v", err)
+	}
+	if rx, tx := channel.DecodeRxTone(), channel.DecodeTxTone(); rx != "Off" || tx != "Off" {
+		t.Errorf("rejected tones were written: rx %q, tx %q", rx, tx)
+	}
+}
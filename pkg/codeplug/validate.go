@@ -0,0 +1,117 @@
+package codeplug
+
+import (
+	"fmt"
+)
+
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+type Issue struct {
+	Severity Severity
+	Check    string
+	Message  string
+}
+
+type validationCheck struct {
+	name string
+	run  func(cp *Codeplug) ([]Issue, error)
+}
+
+var validationChecks = []validationCheck{
+	{name: "radio-id-gap", run: checkRadioIDGap},
+	{name: "orphan-radio-id", run: checkOrphanRadioIDs},
+	{name: "bad-channel-name", run: checkChannelNames},
+}
+
+func (cp *Codeplug) Validate() ([]Issue, error) {
+	var issues []Issue
+	for _, check := range validationChecks {
+		found, err := check.run(cp)
+		if err != nil {
+			return nil, fmt.Errorf("%s check failed: %w", check.name, err)
+		}
+		for i := range found {
+			found[i].Check = check.name
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
+
+// checkRadioIDGap confirms that a plausible radio ID entry, by the same test
+// used to locate the radio ID section, starts exactly radioIDSectionGap
+// bytes after the channel list. If the gap is ever a different size every
+// radio ID read is misaligned, so the bytes actually found there are
+// reported to help work out the real layout.
+func checkRadioIDGap(cp *Codeplug) ([]Issue, error) {
+	channelsEndOffset, err := cp.channelsEndOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	gap := make([]byte, radioIDSectionGap)
+	if err := cp.readAt(gap, channelsEndOffset); err != nil {
+		return nil, fmt.Errorf("failed to read bytes after the channel list at offset %d: %w", channelsEndOffset, err)
+	}
+
+	radioIDOffset := channelsEndOffset + radioIDSectionGap
+	if cp.isRadioIDEntryAt(radioIDOffset) {
+		return nil, nil
+	}
+
+	return []Issue{{
+		Severity: SeverityError,
+		Message: fmt.Sprintf("no valid radio ID entry at offset %d; the %d bytes after the channel list at offset %d are % x",
+			radioIDOffset, radioIDSectionGap, channelsEndOffset, gap),
+	}}, nil
+}
+
+func checkOrphanRadioIDs(cp *Codeplug) ([]Issue, error) {
+	orphans, err := cp.OrphanRadioIDChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(orphans))
+	for _, channel := range orphans {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("channel %d (%s) references radio ID %d, which does not exist", channel.Index, channel.Name, channel.RadioId),
+		})
+	}
+	return issues, nil
+}
+
+func checkChannelNames(cp *Codeplug) ([]Issue, error) {
+	model, err := cp.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	err = cp.ForEachChannel(func(channel *Channel) error {
+		for _, problem := range model.ChannelNameProblems(channel.Name) {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("channel %d name %q: %s", channel.Index, channel.Name, problem),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
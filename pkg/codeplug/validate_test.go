@@ -0,0 +1,44 @@
+package codeplug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRadioIDGap(t *testing.T) {
+	tests := []struct {
+		name     string
+		radioIDs []RadioIDEntry
+		issues   int
+	}{
+		{"valid entry", testRadioIDs, 0},
+		{"zero ID", []RadioIDEntry{{Index: 0, ID: 0, Name: "Radio ID 1"}}, 1},
+		{"no entries", nil, 1},
+	}
+
+	for _, tt := range tests {
+		cp := NewFromBytes(buildCodeplug(testChannels, tt.radioIDs))
+		issues, err := checkRadioIDGap(cp)
+		if err != nil {
+			t.Fatalf("%s: checkRadioIDGap: %v", tt.name, err)
+		}
+		if len(issues) != tt.issues {
+			t.Errorf("%s: got %d issues, want %d", tt.name, len(issues), tt.issues)
+		}
+	}
+}
+
+func TestCheckChannelNamesReportsLongName(t *testing.T) {
+	channels := append([]testChannel(nil), testChannels...)
+	channels[1].name = "A name of 20 chars!!"
+	cp := NewFromBytes(buildCodeplug(channels, testRadioIDs))
+
+	checkCodeplug(t, cp, channels, testRadioIDs)
+	issues, err := checkChannelNames(cp)
+	if err != nil {
+		t.Fatalf("checkChannelNames: %v", err)
+	}
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "too long") {
+		t.Errorf("issues = %v, want one too-long name", issues)
+	}
+}